@@ -1,12 +1,13 @@
 import React, { useState, useEffect } from 'react';
 import { useMutation } from '@apollo/client';
 import { Button, CircularProgress } from '@mui/material';
-import { Add, Check } from '@mui/icons-material';
-import { FOLLOW_USER, UNFOLLOW_USER } from '../graphql/mutations';
+import { Add, Check, Schedule } from '@mui/icons-material';
+import { CANCEL_FOLLOW_REQUEST, FOLLOW_USER, UNFOLLOW_USER } from '../graphql/mutations';
 
 interface FollowButtonProps {
   userIdToFollow: string;
   initialIsFollowing: boolean;
+  initialFollowRequested?: boolean; // Pending request to a private account
   currentUserId?: string | null;
   // Add callback prop to notify parent of status change
   onUpdate?: (userId: string, newStatus: boolean) => void;
@@ -15,20 +16,29 @@ interface FollowButtonProps {
 const FollowButton: React.FC<FollowButtonProps> = ({
   userIdToFollow,
   initialIsFollowing,
+  initialFollowRequested = false,
   currentUserId,
   onUpdate, // Destructure the new prop
 }) => {
   // Internal state still useful for reflecting prop changes
   const [isFollowing, setIsFollowing] = useState(initialIsFollowing);
+  const [isRequested, setIsRequested] = useState(initialFollowRequested);
 
   useEffect(() => {
     setIsFollowing(initialIsFollowing);
   }, [initialIsFollowing]);
 
+  useEffect(() => {
+    setIsRequested(initialFollowRequested);
+  }, [initialFollowRequested]);
+
   const [followUser, { loading: followLoading }] = useMutation(FOLLOW_USER, {
-      onCompleted: () => {
-        setIsFollowing(true);
-        onUpdate?.(userIdToFollow, true); // Call callback on success
+      onCompleted: (data) => {
+        // Private accounts answer with a pending request rather than a follow
+        const following = !!data?.followUser?.isFollowing;
+        setIsFollowing(following);
+        setIsRequested(!!data?.followUser?.followRequested);
+        onUpdate?.(userIdToFollow, following); // Call callback on success
       },
       onError: (err) => {
           console.error("Follow Error:", err);
@@ -50,10 +60,17 @@ const FollowButton: React.FC<FollowButtonProps> = ({
       }
   });
 
+  const [cancelFollowRequest, { loading: cancelLoading }] = useMutation(CANCEL_FOLLOW_REQUEST, {
+      onCompleted: () => setIsRequested(false),
+      onError: (err) => console.error("Cancel Follow Request Error:", err),
+  });
+
   const handleFollowToggle = () => {
     if (isLoading) return; // Prevent double clicks
 
-    if (isFollowing) {
+    if (isRequested) {
+        cancelFollowRequest({ variables: { targetId: userIdToFollow } });
+    } else if (isFollowing) {
         unfollowUser({ variables: { userIdToUnfollow: userIdToFollow } });
     } else {
         followUser({ variables: { userIdToFollow: userIdToFollow } });
@@ -64,18 +81,18 @@ const FollowButton: React.FC<FollowButtonProps> = ({
     return null;
   }
 
-  const isLoading = followLoading || unfollowLoading;
+  const isLoading = followLoading || unfollowLoading || cancelLoading;
 
   return (
     <Button
-      variant={isFollowing ? "outlined" : "contained"}
+      variant={isFollowing || isRequested ? "outlined" : "contained"}
       size="small"
       onClick={handleFollowToggle}
       disabled={isLoading}
-      startIcon={isLoading ? <CircularProgress size={16} color="inherit" /> : (isFollowing ? <Check /> : <Add />)}
+      startIcon={isLoading ? <CircularProgress size={16} color="inherit" /> : (isFollowing ? <Check /> : (isRequested ? <Schedule /> : <Add />))}
       sx={{ ml: 1, textTransform: 'none', minWidth: '80px', height: '28px' }} // Adjust styling
     >
-      {isLoading ? '...' : (isFollowing ? 'Following' : 'Follow')}
+      {isLoading ? '...' : (isFollowing ? 'Following' : (isRequested ? 'Requested' : 'Follow'))}
     </Button>
   );
 };
@@ -1,6 +1,6 @@
 import React, { useState, useEffect } from 'react'; // Import useEffect
 import { useNavigate, useLocation } from 'react-router-dom';
-import { useQuery } from '@apollo/client'; // Import useQuery
+import { useMutation, useQuery } from '@apollo/client'; // Import useQuery and useMutation
 import {
   AppBar,
   Toolbar,
@@ -32,7 +32,7 @@ import {
   Notifications as NotificationsIcon, // Import NotificationsIcon
 } from '@mui/icons-material';
 import { supabase } from '../lib/supabase';
-import { GET_MY_NOTIFICATIONS } from '../graphql/queries'; // Import the query
+import { MARK_ALL_NOTIFICATIONS_READ, UNREAD_NOTIFICATION_COUNT, UNREAD_NOTIFICATION_COUNT_CHANGED } from '../graphql/queries';
 
 export default function Navigation() {
   const navigate = useNavigate();
@@ -44,17 +44,25 @@ export default function Navigation() {
   const [mobileOpen, setMobileOpen] = useState(false);
   const [user, setUser] = useState<any>(null);
 
-  // Fetch Notifications Query
-  const { data: notificationData, loading: notificationLoading, error: notificationError, refetch: refetchNotifications } = useQuery(GET_MY_NOTIFICATIONS, {
-    variables: { filter: 'unread', limit: 100 }, // Fetch up to 100 unread notifications for count
+  // Unread count for the badge; the server pushes the new count whenever it changes.
+  const { data: notificationData, loading: notificationLoading, error: notificationError, refetch: refetchNotifications, subscribeToMore } = useQuery(UNREAD_NOTIFICATION_COUNT, {
     skip: !user, // Skip query if user is not logged in
-    pollInterval: 30000, // Optional: Poll every 30 seconds
     fetchPolicy: 'network-only', // Ensure fresh data is fetched
-    notifyOnNetworkStatusChange: true, // Useful for showing loading states
   });
+  const [markAllNotificationsRead] = useMutation(MARK_ALL_NOTIFICATIONS_READ);
 
-  // Calculate unread count
-  const unreadCount = notificationData?.getMyNotifications?.length || 0;
+  useEffect(() => {
+    if (!user) return;
+    return subscribeToMore({
+      document: UNREAD_NOTIFICATION_COUNT_CHANGED,
+      updateQuery: (prev, { subscriptionData }) => {
+        const count = subscriptionData.data?.unreadNotificationCount;
+        return count === undefined ? prev : { ...prev, unreadNotificationCount: count };
+      },
+    });
+  }, [user, subscribeToMore]);
+
+  const unreadCount = notificationData?.unreadNotificationCount || 0;
 
   // Check if user is logged in and refetch notifications on user change
   useEffect(() => {
@@ -103,6 +111,11 @@ export default function Navigation() {
   };
 
   const handleNotificationClick = () => {
+    // Opening notifications clears the badge; the count subscription brings it back to 0 on other tabs too.
+    if (unreadCount > 0) {
+      markAllNotificationsRead({ variables: { before: new Date().toISOString() } })
+        .catch((err) => console.error('Error marking notifications read:', err));
+    }
     // Navigate to a notifications page (create this route/page)
     navigate('/notifications');
      if(isMobile) handleDrawerToggle(); // Close drawer if mobile
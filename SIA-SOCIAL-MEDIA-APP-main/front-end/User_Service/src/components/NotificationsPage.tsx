@@ -40,6 +40,13 @@ interface FeedPost {
   author: FeedPostAuthor;
 }
 
+interface FeedConnection {
+  edges: { cursor: string; node: FeedPost }[];
+  pageInfo: { hasNextPage: boolean; endCursor?: string | null };
+}
+
+const FEED_PAGE_SIZE = 20;
+
 // Interface for current user data from Supabase (keep as is)
 interface Account {
     id: string;
@@ -84,10 +91,10 @@ const NotificationsPage: React.FC = () => {
 
 
     // *** CHANGE: Fetch the feed using GET_FEED query ***
-    const { data, loading, error, refetch } = useQuery<{ getFeed: FeedPost[] }>(
+    const { data, loading, error, refetch, fetchMore } = useQuery<{ getFeed: FeedConnection }>(
         GET_FEED, // Use the feed query
         {
-            variables: { limit: 20, offset: 0 }, // Example pagination
+            variables: { first: FEED_PAGE_SIZE },
             fetchPolicy: 'cache-and-network',
             skip: !currentUser, // Don't run query until current user is loaded
             notifyOnNetworkStatusChange: true,
@@ -106,6 +113,24 @@ const NotificationsPage: React.FC = () => {
         refetch(); // Simple refetch for now
     }, [refetch]);
 
+    // Append the next page. Cursors are keyset positions, so posts published meanwhile don't shift the list.
+    const loadMore = useCallback(() => {
+        const pageInfo = data?.getFeed.pageInfo;
+        if (!pageInfo?.hasNextPage) return;
+        fetchMore({
+            variables: { first: FEED_PAGE_SIZE, after: pageInfo.endCursor },
+            updateQuery: (prev, { fetchMoreResult }) => {
+                if (!fetchMoreResult) return prev;
+                return {
+                    getFeed: {
+                        ...fetchMoreResult.getFeed,
+                        edges: [...prev.getFeed.edges, ...fetchMoreResult.getFeed.edges],
+                    },
+                };
+            },
+        });
+    }, [data, fetchMore]);
+
     // --- Render Logic ---
     const isLoading = (loading && !data) || currentUserLoading;
 
@@ -122,7 +147,8 @@ const NotificationsPage: React.FC = () => {
     }
 
     // *** CHANGE: Access data using data.getFeed ***
-    const posts = data?.getFeed || [];
+    const posts = data?.getFeed.edges.map((edge) => edge.node) || [];
+    const hasNextPage = data?.getFeed.pageInfo.hasNextPage ?? false;
 
     return (
         <Container maxWidth="md" sx={{ mt: 2, mb: 4 }}>
@@ -188,7 +214,13 @@ const NotificationsPage: React.FC = () => {
                     ))
                 )}
             </List>
-            {/* Optional: Add Load More Button/Logic here */}
+            {hasNextPage && (
+                <Box display="flex" justifyContent="center" sx={{ mt: 2 }}>
+                    <Button variant="outlined" onClick={loadMore} disabled={loading}>
+                        {loading ? 'Loading...' : 'Load more'}
+                    </Button>
+                </Box>
+            )}
         </Container>
     );
 };
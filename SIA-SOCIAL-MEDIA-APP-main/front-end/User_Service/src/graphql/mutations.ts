@@ -6,6 +6,7 @@ export const FOLLOW_USER = gql`
     followUser(userIdToFollow: $userIdToFollow) {
       accountId # Return fields needed to update cache, if any
       isFollowing # Crucially, return the NEW follow status
+      followRequested # Private accounts get a request instead of a follow
     }
   }
 `;
@@ -17,4 +18,130 @@ export const UNFOLLOW_USER = gql`
       isFollowing # Crucially, return the NEW follow status
     }
   }
-`;
\ No newline at end of file
+`;
+
+// Withdraws a pending request to follow a private account
+export const CANCEL_FOLLOW_REQUEST = gql`
+  mutation CancelFollowRequest($targetId: ID!) {
+    cancelFollowRequest(targetId: $targetId)
+  }
+`;
+
+export const SET_ACCOUNT_PRIVACY = gql`
+  mutation SetAccountPrivacy($isPrivate: Boolean!) {
+    setAccountPrivacy(isPrivate: $isPrivate) {
+      accountId
+      isPrivate
+    }
+  }
+`;
+
+export const APPROVE_FOLLOW_REQUEST = gql`
+  mutation ApproveFollowRequest($requesterId: ID!) {
+    approveFollowRequest(requesterId: $requesterId)
+  }
+`;
+
+export const REJECT_FOLLOW_REQUEST = gql`
+  mutation RejectFollowRequest($requesterId: ID!) {
+    rejectFollowRequest(requesterId: $requesterId)
+  }
+`;
+
+// Blocking also removes follows in both directions
+export const BLOCK_USER = gql`
+  mutation BlockUser($accountId: ID!) {
+    blockUser(accountId: $accountId)
+  }
+`;
+
+export const UNBLOCK_USER = gql`
+  mutation UnblockUser($accountId: ID!) {
+    unblockUser(accountId: $accountId)
+  }
+`;
+
+// Muting hides the account's posts and notifications; the muted account is not told
+export const MUTE_USER = gql`
+  mutation MuteUser($accountId: ID!) {
+    muteUser(accountId: $accountId)
+  }
+`;
+
+export const UNMUTE_USER = gql`
+  mutation UnmuteUser($accountId: ID!) {
+    unmuteUser(accountId: $accountId)
+  }
+`;
+
+const MESSAGE_FIELDS = `
+  messageId
+  conversationId
+  body
+  createdAt
+  editedAt
+  deletedAt
+  sender {
+    accountId
+    firstName
+    lastName
+  }
+`;
+
+// One other member and no title starts (or returns the existing) direct conversation; more make a group
+export const START_CONVERSATION = gql`
+  mutation StartConversation($memberIds: [ID!]!, $title: String) {
+    startConversation(memberIds: $memberIds, title: $title) {
+      conversationId
+      isGroup
+      title
+      members {
+        account {
+          accountId
+          firstName
+          lastName
+        }
+      }
+    }
+  }
+`;
+
+export const SEND_MESSAGE = gql`
+  mutation SendMessage($conversationId: ID!, $body: String!) {
+    sendMessage(conversationId: $conversationId, body: $body) {
+      ${MESSAGE_FIELDS}
+    }
+  }
+`;
+
+export const EDIT_MESSAGE = gql`
+  mutation EditMessage($messageId: ID!, $body: String!) {
+    editMessage(messageId: $messageId, body: $body) {
+      ${MESSAGE_FIELDS}
+    }
+  }
+`;
+
+// Leaves a placeholder without a body; returns false if it was already deleted
+export const DELETE_MESSAGE = gql`
+  mutation DeleteMessage($messageId: ID!) {
+    deleteMessage(messageId: $messageId)
+  }
+`;
+
+// Without messageId, marks everything up to the newest message read
+export const MARK_CONVERSATION_READ = gql`
+  mutation MarkConversationRead($conversationId: ID!, $messageId: ID) {
+    markConversationRead(conversationId: $conversationId, messageId: $messageId) {
+      conversationId
+      unreadCount
+      members {
+        account {
+          accountId
+        }
+        lastReadMessageId
+        lastReadAt
+      }
+    }
+  }
+`;
@@ -3,8 +3,94 @@ import { gql } from '@apollo/client';
 
 // Query to fetch notifications for the logged-in user
 export const GET_MY_NOTIFICATIONS = gql`
-  query GetMyNotifications($limit: Int, $offset: Int, $filter: String) {
-    getMyNotifications(limit: $limit, offset: $offset, filter: $filter) {
+  query GetMyNotifications($first: Int, $after: String, $filter: NotificationFilter) {
+    getMyNotifications(first: $first, after: $after, filter: $filter) {
+      edges {
+        cursor
+        node {
+          notificationId
+          notificationType
+          entityId
+          isRead
+          createdAt
+          triggeringUser {
+            accountId
+            firstName
+            lastName
+          }
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+`;
+
+// Notifications grouped by type, entity and time window ("Alice and 4 others liked your post")
+export const GET_MY_NOTIFICATION_GROUPS = gql`
+  query GetMyNotificationGroups($first: Int, $after: String, $filter: NotificationFilter) {
+    getMyNotificationGroups(first: $first, after: $after, filter: $filter) {
+      edges {
+        cursor
+        node {
+          groupId
+          notificationType
+          entityId
+          actors {
+            accountId
+            firstName
+            lastName
+          }
+          actorCount
+          notificationCount
+          unreadCount
+          latestAt
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+`;
+
+// Expands one notification group into its individual notifications
+export const GET_NOTIFICATION_GROUP_ITEMS = gql`
+  query GetNotificationGroupItems($groupId: ID!, $first: Int, $after: String) {
+    notificationGroup(groupId: $groupId) {
+      groupId
+      notifications(first: $first, after: $after) {
+        edges {
+          cursor
+          node {
+            notificationId
+            notificationType
+            entityId
+            isRead
+            createdAt
+            triggeringUser {
+              accountId
+              firstName
+              lastName
+            }
+          }
+        }
+        pageInfo {
+          hasNextPage
+          endCursor
+        }
+      }
+    }
+  }
+`;
+
+// Pushes each new notification for the logged-in user (graphql-ws transport)
+export const NOTIFICATION_ADDED = gql`
+  subscription NotificationAdded {
+    notificationAdded {
       notificationId
       notificationType
       entityId
@@ -19,19 +105,330 @@ export const GET_MY_NOTIFICATIONS = gql`
   }
 `;
 
-// Query for listing posts (ensure it includes author and isFollowing)
-export const LIST_POSTS = gql`
-  query ListPosts { # Add arguments like limit/offset if needed
-    listPosts {
-      postId
-      title
-      content
-      createdAt
-      author {
+// Unread notification count for the navigation badge
+export const UNREAD_NOTIFICATION_COUNT = gql`
+  query UnreadNotificationCount {
+    unreadNotificationCount
+  }
+`;
+
+// Pushes the unread count now and whenever it changes (graphql-ws transport)
+export const UNREAD_NOTIFICATION_COUNT_CHANGED = gql`
+  subscription UnreadNotificationCountChanged {
+    unreadNotificationCount
+  }
+`;
+
+// Marks the given notifications read; returns the new unread count
+export const MARK_NOTIFICATIONS_READ = gql`
+  mutation MarkNotificationsRead($ids: [ID!]!) {
+    markNotificationsRead(ids: $ids)
+  }
+`;
+
+// Marks every notification up to `before` (ISO timestamp) read; returns the new unread count
+export const MARK_ALL_NOTIFICATIONS_READ = gql`
+  mutation MarkAllNotificationsRead($before: String) {
+    markAllNotificationsRead(before: $before)
+  }
+`;
+
+const NOTIFICATION_PREFERENCES_FIELDS = `
+  types {
+    notificationType
+    inApp
+    email
+    webhook
+  }
+  webhookUrl
+  mutedAuthors {
+    accountId
+    firstName
+    lastName
+  }
+`;
+
+// Per-type delivery channels, webhook URL and muted authors of the logged-in user
+export const MY_NOTIFICATION_PREFERENCES = gql`
+  query MyNotificationPreferences {
+    myNotificationPreferences {
+      ${NOTIFICATION_PREFERENCES_FIELDS}
+    }
+  }
+`;
+
+// Omitted channels/webhookUrl keep their current values; webhookUrl "" removes the URL
+export const UPDATE_NOTIFICATION_PREFERENCES = gql`
+  mutation UpdateNotificationPreferences($input: NotificationPreferencesInput!) {
+    updateNotificationPreferences(input: $input) {
+      ${NOTIFICATION_PREFERENCES_FIELDS}
+    }
+  }
+`;
+
+// Stops / resumes NEW_POST notifications about one author
+export const MUTE_NEW_POST_NOTIFICATIONS = gql`
+  mutation MuteNewPostNotifications($authorId: ID!) {
+    muteNewPostNotifications(authorId: $authorId)
+  }
+`;
+
+export const UNMUTE_NEW_POST_NOTIFICATIONS = gql`
+  mutation UnmuteNewPostNotifications($authorId: ID!) {
+    unmuteNewPostNotifications(authorId: $authorId)
+  }
+`;
+
+// Profile header: follow counts and relationship with the logged-in user
+export const GET_ACCOUNT_FOLLOW_SUMMARY = gql`
+  query GetAccountFollowSummary($accountId: ID!) {
+    getAccount(accountId: $accountId) {
+      accountId
+      firstName
+      lastName
+      followerCount
+      followingCount
+      isPrivate
+      isFollowing
+      followRequested
+      followsYou
+    }
+  }
+`;
+
+const FOLLOW_LIST_FIELDS = `
+  edges {
+    cursor
+    node {
+      accountId
+      firstName
+      lastName
+      isFollowing
+      followsYou
+    }
+  }
+  pageInfo {
+    hasNextPage
+    endCursor
+  }
+`;
+
+// Accounts following / followed by an account, most recent follow first
+export const GET_FOLLOWERS = gql`
+  query GetFollowers($accountId: ID!, $first: Int, $after: String) {
+    getAccount(accountId: $accountId) {
+      accountId
+      followers(first: $first, after: $after) {
+        ${FOLLOW_LIST_FIELDS}
+      }
+    }
+  }
+`;
+
+export const GET_FOLLOWING = gql`
+  query GetFollowing($accountId: ID!, $first: Int, $after: String) {
+    getAccount(accountId: $accountId) {
+      accountId
+      following(first: $first, after: $after) {
+        ${FOLLOW_LIST_FIELDS}
+      }
+    }
+  }
+`;
+
+// Friends of friends to follow, ranked by how many followed accounts follow them
+export const SUGGESTED_ACCOUNTS = gql`
+  query SuggestedAccounts($first: Int) {
+    suggestedAccounts(first: $first) {
+      account {
+        accountId
+        firstName
+        lastName
+        isFollowing
+        followRequested
+      }
+      mutualCount
+      mutualFollowers {
         accountId
         firstName
         lastName
-        isFollowing # Fetch the new field
+      }
+    }
+  }
+`;
+
+// Requests to follow the logged-in user's private account
+export const PENDING_FOLLOW_REQUESTS = gql`
+  query PendingFollowRequests($first: Int, $after: String) {
+    pendingFollowRequests(first: $first, after: $after) {
+      edges {
+        cursor
+        node {
+          createdAt
+          requester {
+            accountId
+            firstName
+            lastName
+          }
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+`;
+
+// Accounts the logged-in user blocked / muted, most recent first
+export const BLOCKED_ACCOUNTS = gql`
+  query BlockedAccounts($first: Int, $after: String) {
+    blockedAccounts(first: $first, after: $after) {
+      edges {
+        cursor
+        node {
+          accountId
+          firstName
+          lastName
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+`;
+
+export const MUTED_ACCOUNTS = gql`
+  query MutedAccounts($first: Int, $after: String) {
+    mutedAccounts(first: $first, after: $after) {
+      edges {
+        cursor
+        node {
+          accountId
+          firstName
+          lastName
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+`;
+
+const MESSAGE_FIELDS = `
+  messageId
+  conversationId
+  body
+  createdAt
+  editedAt
+  deletedAt
+  sender {
+    accountId
+    firstName
+    lastName
+  }
+`;
+
+// Inbox: the logged-in user's conversations, most recent message first
+export const CONVERSATIONS = gql`
+  query Conversations($first: Int, $after: String) {
+    conversations(first: $first, after: $after) {
+      edges {
+        cursor
+        node {
+          conversationId
+          isGroup
+          title
+          unreadCount
+          lastMessageAt
+          members {
+            account {
+              accountId
+              firstName
+              lastName
+            }
+          }
+          lastMessage {
+            ${MESSAGE_FIELDS}
+          }
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+`;
+
+// Message history, newest first; pass endCursor as before to load older messages.
+// Members' read positions drive the "seen" receipts.
+export const CONVERSATION_MESSAGES = gql`
+  query ConversationMessages($conversationId: ID!, $first: Int, $before: String) {
+    conversation(conversationId: $conversationId) {
+      conversationId
+      isGroup
+      title
+      members {
+        account {
+          accountId
+          firstName
+          lastName
+        }
+        lastReadMessageId
+        lastReadAt
+      }
+    }
+    messages(conversationId: $conversationId, first: $first, before: $before) {
+      edges {
+        cursor
+        node {
+          ${MESSAGE_FIELDS}
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+`;
+
+// Pushes new, edited and deleted messages in the logged-in user's conversations (graphql-ws transport)
+export const MESSAGE_RECEIVED = gql`
+  subscription MessageReceived {
+    messageReceived {
+      ${MESSAGE_FIELDS}
+    }
+  }
+`;
+
+// Query for listing posts (ensure it includes author and isFollowing)
+export const LIST_POSTS = gql`
+  query ListPosts($first: Int, $after: String) {
+    listPosts(first: $first, after: $after) {
+      edges {
+        cursor
+        node {
+          postId
+          title
+          content
+          createdAt
+          author {
+            accountId
+            firstName
+            lastName
+            isFollowing # Fetch the new field
+          }
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
       }
     }
   }
@@ -39,18 +436,27 @@ export const LIST_POSTS = gql`
 
 // *** ADD THIS QUERY DEFINITION ***
 export const GET_FEED = gql`
-  query GetFeed($limit: Int, $offset: Int) {
+  query GetFeed($first: Int, $after: String) {
     # Use the exact query name from your backend schema
-    getFeed(limit: $limit, offset: $offset) {
-      postId
-      title
-      content
-      createdAt
-      author {
-        accountId
-        firstName
-        lastName
-        isFollowing # Needed for FollowButton
+    getFeed(first: $first, after: $after) {
+      edges {
+        cursor
+        node {
+          postId
+          title
+          content
+          createdAt
+          author {
+            accountId
+            firstName
+            lastName
+            isFollowing # Needed for FollowButton
+          }
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
       }
     }
   }
@@ -1,5 +1,8 @@
-import { ApolloClient, InMemoryCache, createHttpLink, from } from '@apollo/client';
+import { ApolloClient, InMemoryCache, createHttpLink, from, split } from '@apollo/client';
 import { setContext } from '@apollo/client/link/context';
+import { GraphQLWsLink } from '@apollo/client/link/subscriptions';
+import { getMainDefinition } from '@apollo/client/utilities';
+import { createClient } from 'graphql-ws';
 import { supabase } from './supabase';
 
 const httpLink = createHttpLink({
@@ -18,8 +21,28 @@ const authLink = setContext(async (_, { headers }) => {
   };
 });
 
+// Subscriptions use the graphql-ws protocol on the same endpoint. Browsers cannot set headers on a
+// websocket, so the token goes in the connection_init payload, read on every (re)connect.
+const wsLink = new GraphQLWsLink(createClient({
+  url: 'ws://localhost:8080/query',
+  connectionParams: async () => {
+    const { data: { session } } = await supabase.auth.getSession();
+    const token = session?.access_token;
+    return token ? { Authorization: `Bearer ${token}` } : {};
+  },
+}));
+
+const link = split(
+  ({ query }) => {
+    const definition = getMainDefinition(query);
+    return definition.kind === 'OperationDefinition' && definition.operation === 'subscription';
+  },
+  wsLink,
+  from([authLink, httpLink]),
+);
+
 export const client = new ApolloClient({
-  link: from([authLink, httpLink]),
+  link,
   cache: new InMemoryCache(),
 });
 
@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minJWKSReload bounds how often an unknown "kid" may force a reload, so tokens with random key IDs
+// cannot turn the server into a JWKS request amplifier.
+const minJWKSReload = 30 * time.Second
+
+// jwksKeySet caches the public keys of a JWKS document read from a file path or an http(s) URL.
+//
+// Keys are reloaded when the cache is older than maxAge, and early when a token names a key ID the cache
+// does not know (the usual sign of key rotation). Loading happens outside mu and is shared by concurrent
+// callers, so verifications that the cached keys can serve never wait behind a slow fetch.
+type jwksKeySet struct {
+	source string
+	maxAge time.Duration
+	client *http.Client
+
+	mu         sync.Mutex
+	keys       map[string]interface{} // kid -> *rsa.PublicKey or *ecdsa.PublicKey
+	loadedAt   time.Time
+	lastReload time.Time
+	inflight   *jwksReload // non-nil while a reload is running
+}
+
+// jwksReload is one load of the JWKS document; done is closed once keys and err are set.
+type jwksReload struct {
+	done chan struct{}
+	keys map[string]interface{} // the keys in use afterwards: the new ones, or the previous ones on failure
+	err  error
+}
+
+func newJWKSKeySet(source string, maxAge time.Duration) *jwksKeySet {
+	return &jwksKeySet{
+		source: source,
+		maxAge: maxAge,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// key returns the public key with the given ID. An empty kid is accepted when the set holds exactly one key.
+func (s *jwksKeySet) key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	keys := s.keys
+	var r *jwksReload
+	if keys == nil || (time.Since(s.loadedAt) > s.maxAge && time.Since(s.lastReload) >= minJWKSReload) {
+		r = s.startReload()
+	}
+	s.mu.Unlock()
+
+	// Stale keys keep serving while the reload runs; only the very first load has to be waited for.
+	if keys == nil {
+		var err error
+		if keys, err = r.wait(ctx); keys == nil {
+			return nil, err
+		}
+	}
+	if k, ok := lookup(keys, kid); ok {
+		return k, nil
+	}
+
+	s.mu.Lock()
+	r = nil
+	if s.inflight != nil || time.Since(s.lastReload) >= minJWKSReload {
+		r = s.startReload()
+	}
+	s.mu.Unlock()
+	if r != nil {
+		keys, err := r.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if k, ok := lookup(keys, kid); ok {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("no key with kid %q in JWKS %s", kid, s.source)
+}
+
+func lookup(keys map[string]interface{}, kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(keys) == 1 {
+			for _, k := range keys {
+				return k, true
+			}
+		}
+		return nil, false
+	}
+	k, ok := keys[kid]
+	return k, ok
+}
+
+// startReload returns the running reload, starting one if there is none. Callers hold s.mu.
+func (s *jwksKeySet) startReload() *jwksReload {
+	if s.inflight == nil {
+		s.inflight = &jwksReload{done: make(chan struct{})}
+		s.lastReload = time.Now()
+		go s.reload(s.inflight)
+	}
+	return s.inflight
+}
+
+// wait blocks until r is done or ctx ends.
+func (r *jwksReload) wait(ctx context.Context) (map[string]interface{}, error) {
+	select {
+	case <-r.done:
+		return r.keys, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// reload loads the document and swaps the new keys in. On failure the previous keys stay in use. It runs
+// detached from any request, so one caller giving up does not fail the load for the others.
+func (s *jwksKeySet) reload(r *jwksReload) {
+	keys, err := s.load()
+
+	s.mu.Lock()
+	if err == nil {
+		s.keys = keys
+		s.loadedAt = time.Now()
+	}
+	r.keys, r.err = s.keys, err
+	s.inflight = nil
+	s.mu.Unlock()
+	close(r.done)
+}
+
+func (s *jwksKeySet) load() (map[string]interface{}, error) {
+	data, err := s.fetch(context.Background())
+	if err != nil {
+		log.Printf("auth: failed to load JWKS %s: %v", s.source, err)
+		return nil, err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		log.Printf("auth: failed to parse JWKS %s: %v", s.source, err)
+		return nil, err
+	}
+	log.Printf("auth: loaded %d key(s) from JWKS %s", len(keys), s.source)
+	return keys, nil
+}
+
+func (s *jwksKeySet) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(s.source, "file://"))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk holds the JSON Web Key members needed for RSA and EC public keys (RFC 7517, RFC 7518 section 6).
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS decodes a JWKS document. Keys that are not signature keys, or of an unsupported type, are skipped.
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	keys := map[string]interface{}{}
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			log.Printf("auth: skipping JWKS key %q: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,81 @@
+// Package auth holds credential handling shared by the resolvers, the HTTP middleware and the admin commands.
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordTooLong is returned for passwords bcrypt cannot hash without truncation.
+var ErrPasswordTooLong = errors.New("password must be at most 72 bytes")
+
+// PasswordHasher hashes and verifies account passwords with bcrypt at a configurable cost.
+type PasswordHasher struct {
+	cost int
+	// dummyHash is compared against when no account matches, so rejecting an unknown email costs as much as
+	// rejecting a wrong password.
+	dummyHash []byte
+}
+
+// NewPasswordHasher returns a hasher using cost, clamped to bcrypt's supported range.
+func NewPasswordHasher(cost int) *PasswordHasher {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	if cost > bcrypt.MaxCost {
+		cost = bcrypt.MaxCost
+	}
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("no account has this password"), cost)
+	return &PasswordHasher{cost: cost, dummyHash: dummyHash}
+}
+
+// Cost returns the bcrypt cost new hashes are produced with.
+func (h *PasswordHasher) Cost() int {
+	return h.cost
+}
+
+// Hash returns the bcrypt hash of password.
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if len(password) > 72 {
+		return "", ErrPasswordTooLong
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify reports whether password matches hash. needsRehash is true when the password matched but hash was
+// produced with a different cost, so the caller should store a fresh Hash(password).
+func (h *PasswordHasher) Verify(hash, password string) (ok bool, needsRehash bool, err error) {
+	if !IsHashed(hash) {
+		// Unmigrated plaintext rows never verify; run cmd/hashpasswords to convert them.
+		return false, false, nil
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true, false, err
+	}
+	return true, cost != h.cost, nil
+}
+
+// Reject compares password against a fixed hash of the configured cost and discards the result. Call it when
+// the account lookup misses, so the response time does not reveal which emails are registered.
+func (h *PasswordHasher) Reject(password string) {
+	_ = bcrypt.CompareHashAndPassword(h.dummyHash, []byte(password))
+}
+
+// IsHashed reports whether stored looks like a bcrypt hash rather than a legacy plaintext password.
+func IsHashed(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
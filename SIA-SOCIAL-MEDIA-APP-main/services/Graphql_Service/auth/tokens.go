@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoSigningKey is returned by NewTokenIssuer when no signing key is configured.
+var ErrNoSigningKey = errors.New("auth: token signing key not set")
+
+// TokenIssuer signs the service's own HS256 access tokens and mints opaque refresh tokens.
+//
+// Refresh tokens are random strings; only their SHA-256 digest is stored, so a leaked table cannot be
+// replayed. Access tokens are short-lived and stateless, refresh tokens are revoked server-side.
+type TokenIssuer struct {
+	key        []byte
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+// NewTokenIssuer returns an issuer signing with key. The key should be at least 32 random bytes.
+func NewTokenIssuer(key, issuer, audience string, accessTTL, refreshTTL time.Duration) (*TokenIssuer, error) {
+	if key == "" {
+		return nil, ErrNoSigningKey
+	}
+	return &TokenIssuer{
+		key:        []byte(key),
+		issuer:     issuer,
+		audience:   audience,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}, nil
+}
+
+// IssuerConfig returns the Verifier entry that accepts this issuer's access tokens.
+func (t *TokenIssuer) IssuerConfig() IssuerConfig {
+	return IssuerConfig{Issuer: t.issuer, Audiences: []string{t.audience}, Secret: t.key}
+}
+
+// AccessToken returns a signed access token for accountID and its expiry.
+func (t *TokenIssuer) AccessToken(accountID string) (string, time.Time, error) {
+	now := t.now()
+	expiresAt := now.Add(t.accessTTL)
+	claims := jwt.RegisteredClaims{
+		Issuer:    t.issuer,
+		Subject:   accountID,
+		Audience:  jwt.ClaimStrings{t.audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign access token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// RefreshToken returns a new random refresh token, the digest to store for it and its expiry.
+func (t *TokenIssuer) RefreshToken() (token string, digest string, expiresAt time.Time, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", time.Time{}, fmt.Errorf("generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, RefreshTokenDigest(token), t.now().Add(t.refreshTTL), nil
+}
+
+// RefreshTokenDigest returns the value stored in place of a refresh token.
+func RefreshTokenDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IssuerConfig describes one token issuer the Verifier trusts. Exactly one of Secret and JWKS is set.
+type IssuerConfig struct {
+	// Issuer must equal the token's "iss" claim. An empty Issuer matches tokens whose "iss" names no other
+	// configured issuer; it is only meant for legacy Supabase secrets and only one may be configured.
+	Issuer string
+	// Audiences, when non-empty, requires the token's "aud" claim to contain at least one of them.
+	Audiences []string
+	// Secret verifies HS256 tokens.
+	Secret []byte
+	// JWKS is a file path or http(s) URL of a JWKS document used to verify RS256/ES256 tokens.
+	JWKS string
+}
+
+// hmacMethods and asymmetricMethods are the algorithms accepted for secret and JWKS issuers.
+// They never overlap, so a public key can never be used as an HMAC secret.
+var (
+	hmacMethods       = []string{"HS256"}
+	asymmetricMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+)
+
+// Verifier validates bearer tokens from any of several issuers: signature, "iss", "aud", "exp" and "nbf".
+type Verifier struct {
+	issuers  map[string]*trustedIssuer
+	fallback *trustedIssuer // the issuer configured with an empty Issuer, if any
+	leeway   time.Duration
+}
+
+type trustedIssuer struct {
+	config  IssuerConfig
+	methods []string
+	jwks    *jwksKeySet
+}
+
+// NewVerifier returns a Verifier for issuers. JWKS documents are reloaded at most every jwksMaxAge, or
+// earlier when a token references an unknown key ID; leeway absorbs clock skew on "exp" and "nbf".
+func NewVerifier(issuers []IssuerConfig, jwksMaxAge, leeway time.Duration) (*Verifier, error) {
+	if len(issuers) == 0 {
+		return nil, errors.New("auth: no token issuers configured")
+	}
+	v := &Verifier{issuers: map[string]*trustedIssuer{}, leeway: leeway}
+	for _, cfg := range issuers {
+		ti := &trustedIssuer{config: cfg}
+		switch {
+		case len(cfg.Secret) > 0 && cfg.JWKS == "":
+			ti.methods = hmacMethods
+		case len(cfg.Secret) == 0 && cfg.JWKS != "":
+			ti.methods = asymmetricMethods
+			ti.jwks = newJWKSKeySet(cfg.JWKS, jwksMaxAge)
+		default:
+			return nil, fmt.Errorf("auth: issuer %q must set exactly one of a secret or a JWKS source", cfg.Issuer)
+		}
+		if cfg.Issuer == "" {
+			if v.fallback != nil {
+				return nil, errors.New("auth: only one issuer may leave the issuer name empty")
+			}
+			v.fallback = ti
+			continue
+		}
+		if _, dup := v.issuers[cfg.Issuer]; dup {
+			return nil, fmt.Errorf("auth: issuer %q configured twice", cfg.Issuer)
+		}
+		v.issuers[cfg.Issuer] = ti
+	}
+	return v, nil
+}
+
+// Verify checks tokenString against the issuer named in its "iss" claim and returns the verified claims.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	// The claims are read unverified only to pick the issuer; the token is fully verified below.
+	unverified := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, unverified); err != nil {
+		return nil, err
+	}
+	iss, _ := unverified.GetIssuer()
+	ti, ok := v.issuers[iss]
+	if !ok {
+		if v.fallback == nil {
+			return nil, fmt.Errorf("untrusted issuer %q", iss)
+		}
+		ti = v.fallback
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(ti.methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.leeway),
+	}
+	if ti.config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(ti.config.Issuer))
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if ti.jwks == nil {
+			return ti.config.Secret, nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		return ti.jwks.key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAudience(claims, ti.config.Audiences); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func checkAudience(claims jwt.MapClaims, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	aud, err := claims.GetAudience()
+	if err != nil {
+		return err
+	}
+	for _, a := range aud {
+		for _, want := range allowed {
+			if a == want {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: audience %v not accepted", jwt.ErrTokenInvalidAudience, []string(aud))
+}
@@ -0,0 +1,77 @@
+// Package cache keeps expensive, briefly stale-tolerant results in process memory.
+//
+// Entries are per process: each replica computes and expires its own, so callers must be fine with replicas
+// disagreeing until entries expire.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL maps keys to values that expire ttl after they were stored. A nil *TTL is a valid cache that stores
+// nothing, so callers need no checks when caching is disabled.
+type TTL[K comparable, V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[K]entry[V]
+	swept   time.Time
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// NewTTL returns an empty cache, or nil (caching disabled) when ttl is not positive.
+func NewTTL[K comparable, V any](ttl time.Duration) *TTL[K, V] {
+	if ttl <= 0 {
+		return nil
+	}
+	return &TTL[K, V]{ttl: ttl, entries: map[K]entry[V]{}, swept: time.Now()}
+}
+
+// Get returns the value stored under key unless it expired.
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key for the cache's TTL. Expired entries are dropped at most once per TTL, so the
+// cache holds no more than the keys set within the last two TTLs.
+func (c *TTL[K, V]) Set(key K, value V) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.swept) > c.ttl {
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.swept = now
+	}
+	c.entries[key] = entry[V]{value: value, expires: now.Add(c.ttl)}
+}
+
+// Delete drops the value stored under key, if any.
+func (c *TTL[K, V]) Delete(key K) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,77 @@
+// Command hashpasswords is a one-off migration that replaces legacy plaintext values in accounts.password
+// with bcrypt hashes. Rows that already hold a hash are left alone, so it is safe to re-run.
+//
+//	go run ./cmd/hashpasswords           # migrate
+//	go run ./cmd/hashpasswords -dry-run  # only count the plaintext rows
+package main
+
+import (
+	"context"
+	"flag"
+	"graphql/auth"
+	"graphql/config"
+	"graphql/store/postgres"
+	"log"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report how many rows would be migrated without changing them")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: Error loading .env file", err)
+	}
+	cfg := config.Load()
+	db, err := postgres.Open(cfg.DB)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	defer db.Close()
+	hasher := auth.NewPasswordHasher(cfg.Auth.PasswordCost)
+	ctx := context.Background()
+
+	rows, err := db.QueryContext(ctx, `SELECT id, password FROM accounts`)
+	if err != nil {
+		log.Fatalf("FATAL: querying accounts: %v", err)
+	}
+	plaintext := map[string]string{}
+	for rows.Next() {
+		var id, password string
+		if err := rows.Scan(&id, &password); err != nil {
+			log.Fatalf("FATAL: scanning account: %v", err)
+		}
+		if !auth.IsHashed(password) {
+			plaintext[id] = password
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatalf("FATAL: reading accounts: %v", err)
+	}
+	rows.Close()
+
+	log.Printf("Found %d account(s) with plaintext passwords", len(plaintext))
+	if *dryRun {
+		return
+	}
+
+	migrated := 0
+	for id, password := range plaintext {
+		hash, err := hasher.Hash(password)
+		if err != nil {
+			log.Printf("Skipping account %s: %v", id, err)
+			continue
+		}
+		// Guard on the old value so a password changed concurrently is not overwritten.
+		result, err := db.ExecContext(ctx, `UPDATE accounts SET password = $1 WHERE id = $2 AND password = $3`, hash, id, password)
+		if err != nil {
+			log.Printf("Failed to update account %s: %v", id, err)
+			continue
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			migrated++
+		}
+	}
+	log.Printf("Hashed %d of %d plaintext password(s) at bcrypt cost %d", migrated, len(plaintext), hasher.Cost())
+}
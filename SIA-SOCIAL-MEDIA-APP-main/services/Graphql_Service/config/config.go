@@ -0,0 +1,174 @@
+package config
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds the runtime settings for the GraphQL service, read from the environment (.env is loaded by main).
+type Config struct {
+	Port string
+	DB   DBConfig
+	Auth AuthConfig
+	Feed FeedConfig
+
+	Suggestions   SuggestionConfig
+	Notifications NotificationConfig
+
+	RabbitMQURL string // RABBITMQ_URL; messages for background workers are dropped when empty
+}
+
+// DBConfig controls the shared Postgres connection pool.
+type DBConfig struct {
+	URL              string
+	MaxOpenConns     int           // DB_MAX_OPEN_CONNS
+	MaxIdleConns     int           // DB_MAX_IDLE_CONNS
+	ConnMaxIdleTime  time.Duration // DB_CONN_MAX_IDLE_TIME, e.g. "5m"
+	ConnMaxLifetime  time.Duration // DB_CONN_MAX_LIFETIME, e.g. "30m"
+	StatementTimeout time.Duration // DB_STATEMENT_TIMEOUT, applied to every resolver query
+}
+
+// AuthConfig controls credential handling.
+type AuthConfig struct {
+	PasswordCost int // PASSWORD_BCRYPT_COST; changing it rehashes passwords on their next successful verification
+
+	TokenSecret     string        // AUTH_TOKEN_SECRET signs our own access tokens; login is disabled when empty
+	TokenIssuer     string        // AUTH_TOKEN_ISSUER, the "iss" claim of our access tokens
+	TokenAudience   string        // AUTH_TOKEN_AUDIENCE, the "aud" claim of our access tokens
+	AccessTokenTTL  time.Duration // ACCESS_TOKEN_TTL, e.g. "15m"
+	RefreshTokenTTL time.Duration // REFRESH_TOKEN_TTL, e.g. "720h"
+
+	SupabaseJWTSecret   string // SUPABASE_JWT_SECRET; optional, Supabase tokens are still accepted when set
+	SupabaseJWTIssuer   string // SUPABASE_JWT_ISSUER, e.g. "https://<project>.supabase.co/auth/v1"; empty accepts any "iss"
+	SupabaseJWTAudience string // SUPABASE_JWT_AUDIENCE
+
+	// TrustedIssuers lists external issuers whose RS256/ES256 tokens are verified against a JWKS document.
+	// AUTH_TRUSTED_ISSUERS holds them as a JSON array, e.g.
+	//	[{"issuer": "https://sso.example.com", "audiences": ["sia"], "jwks": "https://sso.example.com/jwks.json"},
+	//	 {"issuer": "local-dev", "jwks": "./dev/jwks.json"}]
+	TrustedIssuers []TrustedIssuer
+	JWKSMaxAge     time.Duration // AUTH_JWKS_MAX_AGE; JWKS documents are also reloaded when a token names an unknown key
+	ClockSkew      time.Duration // AUTH_CLOCK_SKEW, leeway applied to "exp" and "nbf"
+}
+
+// FeedConfig controls how home timelines are built. Posts are copied into followers' timelines when they are
+// written, except for authors with more than FanoutMaxFollowers followers, whose posts are merged in at read time.
+type FeedConfig struct {
+	FanoutMaxFollowers int // FEED_FANOUT_MAX_FOLLOWERS
+	BackfillPosts      int // FEED_BACKFILL_POSTS, recent posts copied into a timeline on follow
+}
+
+// SuggestionConfig controls the suggestedAccounts query. Each viewer's ranked suggestions are computed from the
+// follow graph and cached in process for CacheTTL; the viewer's own follows, blocks and mutes drop the entry.
+type SuggestionConfig struct {
+	CacheTTL time.Duration // SUGGESTIONS_CACHE_TTL, e.g. "10m"; 0 disables the cache
+}
+
+// NotificationConfig controls how notifications are presented.
+type NotificationConfig struct {
+	// GroupWindow is the width of the fixed time windows, aligned to the Unix epoch, within which notifications
+	// of one type about one entity are grouped (NOTIFICATION_GROUP_WINDOW, e.g. "30m" or "24h"; truncated to
+	// whole seconds).
+	GroupWindow time.Duration
+}
+
+// TrustedIssuer is one entry of AUTH_TRUSTED_ISSUERS.
+type TrustedIssuer struct {
+	Issuer    string   `json:"issuer"`
+	Audiences []string `json:"audiences"`
+	JWKS      string   `json:"jwks"` // file path or http(s) URL
+}
+
+// Load reads the configuration from environment variables, falling back to defaults for anything unset.
+func Load() Config {
+	cfg := Config{
+		Port: getString("PORT", "8080"),
+		DB: DBConfig{
+			URL:              os.Getenv("DATABASE_URL"),
+			MaxOpenConns:     getInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:     getInt("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxIdleTime:  getDuration("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			ConnMaxLifetime:  getDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			StatementTimeout: getDuration("DB_STATEMENT_TIMEOUT", 5*time.Second),
+		},
+		Auth: AuthConfig{
+			PasswordCost:        getInt("PASSWORD_BCRYPT_COST", 12),
+			TokenSecret:         os.Getenv("AUTH_TOKEN_SECRET"),
+			TokenIssuer:         getString("AUTH_TOKEN_ISSUER", "sia-graphql"),
+			AccessTokenTTL:      getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:     getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			TokenAudience:       getString("AUTH_TOKEN_AUDIENCE", "sia-graphql"),
+			SupabaseJWTSecret:   os.Getenv("SUPABASE_JWT_SECRET"),
+			SupabaseJWTIssuer:   os.Getenv("SUPABASE_JWT_ISSUER"),
+			SupabaseJWTAudience: getString("SUPABASE_JWT_AUDIENCE", "authenticated"),
+			TrustedIssuers:      getTrustedIssuers("AUTH_TRUSTED_ISSUERS"),
+			JWKSMaxAge:          getDuration("AUTH_JWKS_MAX_AGE", time.Hour),
+			ClockSkew:           getDuration("AUTH_CLOCK_SKEW", 30*time.Second),
+		},
+		Feed: FeedConfig{
+			FanoutMaxFollowers: getInt("FEED_FANOUT_MAX_FOLLOWERS", 10000),
+			BackfillPosts:      getInt("FEED_BACKFILL_POSTS", 200),
+		},
+		Suggestions: SuggestionConfig{
+			CacheTTL: getDuration("SUGGESTIONS_CACHE_TTL", 10*time.Minute),
+		},
+		RabbitMQURL: os.Getenv("RABBITMQ_URL"),
+		Notifications: NotificationConfig{
+			GroupWindow: getDuration("NOTIFICATION_GROUP_WINDOW", 24*time.Hour).Truncate(time.Second),
+		},
+	}
+	if cfg.Notifications.GroupWindow <= 0 {
+		log.Printf("config: NOTIFICATION_GROUP_WINDOW must be at least 1s, using default 24h")
+		cfg.Notifications.GroupWindow = 24 * time.Hour
+	}
+	return cfg
+}
+
+func getString(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("config: invalid integer for %s (%q), using default %d", key, v, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("config: invalid duration for %s (%q), using default %s", key, v, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getTrustedIssuers(key string) []TrustedIssuer {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+	var issuers []TrustedIssuer
+	if err := json.Unmarshal([]byte(v), &issuers); err != nil {
+		log.Printf("config: invalid JSON for %s, ignoring it: %v", key, err)
+		return nil
+	}
+	return issuers
+}
@@ -1,3 +1,6 @@
+//go:build ignore
+
+// Standalone connectivity check: go run database_check.go
 package main
 
 import (
@@ -2,7 +2,13 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"log"
+	"strings"
+	"time"
 )
 
 // ContextKey defines a type for context keys to avoid collisions.
@@ -31,3 +37,98 @@ func getCurrentUserID(ctx context.Context) (string, error) {
 
 	return userID, nil
 }
+
+// errInvalidCredentials is deliberately vague so callers cannot probe which emails are registered.
+var errInvalidCredentials = errors.New("invalid email or password")
+
+// verifyPassword checks email/password against the stored hash and returns the account ID on success.
+// When the hash was produced with an outdated cost it is transparently replaced with a fresh one.
+func (r *Resolver) verifyPassword(ctx context.Context, email, password string) (string, error) {
+	accountID, passwordHash, err := r.Accounts.GetCredentials(ctx, email)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			r.Passwords.Reject(password)
+			return "", errInvalidCredentials
+		}
+		return "", err
+	}
+	ok, needsRehash, err := r.Passwords.Verify(passwordHash, password)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errInvalidCredentials
+	}
+	if needsRehash {
+		if newHash, err := r.Passwords.Hash(password); err != nil {
+			log.Printf("verifyPassword: failed to rehash password for %s: %v", accountID, err)
+		} else if err := r.Accounts.UpdatePasswordHash(ctx, accountID, newHash); err != nil {
+			log.Printf("verifyPassword: failed to store rehashed password for %s: %v", accountID, err)
+		} else {
+			log.Printf("verifyPassword: rehashed password for %s at cost %d", accountID, r.Passwords.Cost())
+		}
+	}
+	return accountID, nil
+}
+
+// AuthRolesKey holds the []string of roles granted to the authenticated user by the auth middleware,
+// merged from the token claims and the account_roles table.
+const AuthRolesKey ContextKey = "authRoles"
+
+// currentUserHasRole reports whether the authenticated user was granted role. Role names compare
+// case-insensitively, so the "admin" claim satisfies model.RoleAdmin.
+func currentUserHasRole(ctx context.Context, role model.Role) bool {
+	roles, _ := ctx.Value(AuthRolesKey).([]string)
+	for _, r := range roles {
+		if strings.EqualFold(r, string(role)) {
+			return true
+		}
+	}
+	return false
+}
+
+// roleTarget checks that the account named by grantRole/revokeRole exists, or returns a NOT_FOUND error.
+func (r *Resolver) roleTarget(ctx context.Context, accountID string) error {
+	if _, err := r.Accounts.GetByID(ctx, accountID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return codedError(ctx, CodeNotFound, "account not found")
+		}
+		log.Printf("roleTarget DB Error fetching account %s: %v", accountID, err)
+		return fmt.Errorf("internal server error")
+	}
+	return nil
+}
+
+// errLoginDisabled is returned by the session mutations when no token signing key is configured.
+var errLoginDisabled = errors.New("login is not enabled on this server")
+
+// newSession issues an access token and a stored refresh token for accountID.
+func (r *Resolver) newSession(ctx context.Context, accountID string) (*model.AuthPayload, error) {
+	refreshToken, digest, refreshExpiresAt, err := r.Tokens.RefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.RefreshTokens.Create(ctx, store.NewRefreshToken{AccountID: accountID, Digest: digest, ExpiresAt: refreshExpiresAt}); err != nil {
+		return nil, err
+	}
+	return r.sessionPayload(ctx, accountID, refreshToken, refreshExpiresAt)
+}
+
+// sessionPayload signs an access token for accountID and bundles it with an already stored refresh token.
+func (r *Resolver) sessionPayload(ctx context.Context, accountID, refreshToken string, refreshExpiresAt time.Time) (*model.AuthPayload, error) {
+	account, err := r.Accounts.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, accessExpiresAt, err := r.Tokens.AccessToken(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AuthPayload{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt.UTC().Format(time.RFC3339),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshExpiresAt.UTC().Format(time.RFC3339),
+		Account:               account,
+	}, nil
+}
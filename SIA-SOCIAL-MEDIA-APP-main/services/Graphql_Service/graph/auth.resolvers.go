@@ -0,0 +1,130 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.72
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql/auth"
+	"graphql/graph/model"
+	"graphql/store"
+	"log"
+)
+
+// Login is the resolver for the login field.
+func (r *mutationResolver) Login(ctx context.Context, email string, password string) (*model.AuthPayload, error) {
+	if r.Tokens == nil {
+		return nil, errLoginDisabled
+	}
+	accountID, err := r.verifyPassword(ctx, email, password)
+	if err != nil {
+		if errors.Is(err, errInvalidCredentials) {
+			return nil, codedError(ctx, CodeUnauthenticated, err.Error())
+		}
+		log.Printf("Login Error verifying credentials: %v", err)
+		return nil, fmt.Errorf("internal server error")
+	}
+
+	session, err := r.newSession(ctx, accountID)
+	if err != nil {
+		log.Printf("Login Error issuing session for %s: %v", accountID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	log.Printf("Login: issued session for %s", accountID)
+	return session, nil
+}
+
+// RefreshToken is the resolver for the refreshToken field.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model.AuthPayload, error) {
+	if r.Tokens == nil {
+		return nil, errLoginDisabled
+	}
+	nextToken, nextDigest, expiresAt, err := r.Tokens.RefreshToken()
+	if err != nil {
+		log.Printf("RefreshToken Error generating token: %v", err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	accountID, err := r.RefreshTokens.Rotate(ctx, auth.RefreshTokenDigest(refreshToken), nextDigest, expiresAt)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeUnauthenticated, "refresh token is invalid, expired or revoked")
+		}
+		log.Printf("RefreshToken DB Error rotating token: %v", err)
+		return nil, fmt.Errorf("internal server error")
+	}
+
+	session, err := r.sessionPayload(ctx, accountID, nextToken, expiresAt)
+	if err != nil {
+		log.Printf("RefreshToken Error issuing session for %s: %v", accountID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	return session, nil
+}
+
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context, refreshToken string, allSessions *bool) (bool, error) {
+	accountID, err := r.RefreshTokens.Revoke(ctx, auth.RefreshTokenDigest(refreshToken))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return false, nil // Already logged out; nothing to revoke
+		}
+		log.Printf("Logout DB Error revoking token: %v", err)
+		return false, fmt.Errorf("internal server error")
+	}
+
+	if allSessions != nil && *allSessions {
+		revoked, err := r.RefreshTokens.RevokeAll(ctx, accountID)
+		if err != nil {
+			log.Printf("Logout DB Error revoking all sessions for %s: %v", accountID, err)
+			return false, fmt.Errorf("internal server error")
+		}
+		log.Printf("Logout: revoked %d other session(s) for %s", revoked, accountID)
+	}
+	return true, nil
+}
+
+// GrantRole is the resolver for the grantRole field.
+func (r *mutationResolver) GrantRole(ctx context.Context, accountID string, role model.Role) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @hasRole guarantees a user
+	if err != nil {
+		return false, err
+	}
+	if err := r.roleTarget(ctx, accountID); err != nil {
+		return false, err
+	}
+	granted, err := r.Roles.Grant(ctx, accountID, string(role))
+	if err != nil {
+		log.Printf("GrantRole DB Error granting %s to %s: %v", role, accountID, err)
+		return false, fmt.Errorf("internal server error")
+	}
+	if granted {
+		log.Printf("GrantRole: %s granted %s to %s", currentUserID, role, accountID)
+	}
+	return granted, nil
+}
+
+// RevokeRole is the resolver for the revokeRole field.
+func (r *mutationResolver) RevokeRole(ctx context.Context, accountID string, role model.Role) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @hasRole guarantees a user
+	if err != nil {
+		return false, err
+	}
+	if accountID == currentUserID && role == model.RoleAdmin {
+		return false, codedError(ctx, CodeBadUserInput, "admins cannot revoke their own ADMIN role")
+	}
+	if err := r.roleTarget(ctx, accountID); err != nil {
+		return false, err
+	}
+	revoked, err := r.Roles.Revoke(ctx, accountID, string(role))
+	if err != nil {
+		log.Printf("RevokeRole DB Error revoking %s from %s: %v", role, accountID, err)
+		return false, fmt.Errorf("internal server error")
+	}
+	if revoked {
+		log.Printf("RevokeRole: %s revoked %s from %s", currentUserID, role, accountID)
+	}
+	return revoked, nil
+}
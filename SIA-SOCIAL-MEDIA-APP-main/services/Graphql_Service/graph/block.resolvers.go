@@ -0,0 +1,158 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.72
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"log"
+)
+
+// BlockUser is the resolver for the blockUser field.
+func (r *mutationResolver) BlockUser(ctx context.Context, accountID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	if err := r.checkOtherAccount(ctx, "BlockUser", currentUserID, accountID, "block"); err != nil {
+		return false, err
+	}
+
+	blocked, err := r.Blocks.Block(ctx, currentUserID, accountID)
+	if err != nil {
+		log.Printf("BlockUser DB Error (%s -> %s): %v", currentUserID, accountID, err)
+		return false, fmt.Errorf("failed to block user")
+	}
+	// Cut the accounts apart even if the block already existed, so retrying a half-done block finishes it.
+	for _, pair := range [][2]string{{currentUserID, accountID}, {accountID, currentUserID}} {
+		if err := r.severFollow(ctx, pair[0], pair[1]); err != nil {
+			log.Printf("BlockUser DB Error removing follow (%s -> %s): %v", pair[0], pair[1], err)
+			return false, fmt.Errorf("failed to block user")
+		}
+	}
+	log.Printf("User %s blocked %s (new block: %v)", currentUserID, accountID, blocked)
+	r.SuggestionCache.Delete(currentUserID)
+	r.SuggestionCache.Delete(accountID)
+	return blocked, nil
+}
+
+// UnblockUser is the resolver for the unblockUser field.
+func (r *mutationResolver) UnblockUser(ctx context.Context, accountID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	unblocked, err := r.Blocks.Unblock(ctx, currentUserID, accountID)
+	if err != nil {
+		log.Printf("UnblockUser DB Error (%s -> %s): %v", currentUserID, accountID, err)
+		return false, fmt.Errorf("failed to unblock user")
+	}
+	r.SuggestionCache.Delete(currentUserID)
+	return unblocked, nil
+}
+
+// MuteUser is the resolver for the muteUser field.
+func (r *mutationResolver) MuteUser(ctx context.Context, accountID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	if err := r.checkOtherAccount(ctx, "MuteUser", currentUserID, accountID, "mute"); err != nil {
+		return false, err
+	}
+	muted, err := r.Blocks.Mute(ctx, currentUserID, accountID)
+	if err != nil {
+		log.Printf("MuteUser DB Error (%s -> %s): %v", currentUserID, accountID, err)
+		return false, fmt.Errorf("failed to mute user")
+	}
+	r.SuggestionCache.Delete(currentUserID)
+	return muted, nil
+}
+
+// UnmuteUser is the resolver for the unmuteUser field.
+func (r *mutationResolver) UnmuteUser(ctx context.Context, accountID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	unmuted, err := r.Blocks.Unmute(ctx, currentUserID, accountID)
+	if err != nil {
+		log.Printf("UnmuteUser DB Error (%s -> %s): %v", currentUserID, accountID, err)
+		return false, fmt.Errorf("failed to unmute user")
+	}
+	r.SuggestionCache.Delete(currentUserID)
+	return unmuted, nil
+}
+
+// BlockedAccounts is the resolver for the blockedAccounts field.
+func (r *queryResolver) BlockedAccounts(ctx context.Context, first *int32, after *string) (*model.AccountConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.Blocks.ListBlocked(ctx, currentUserID, cursor, limit)
+	if err != nil {
+		log.Printf("BlockedAccounts DB Error for user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to list blocked accounts")
+	}
+	return accountConnection(edges, limit), nil
+}
+
+// MutedAccounts is the resolver for the mutedAccounts field.
+func (r *queryResolver) MutedAccounts(ctx context.Context, first *int32, after *string) (*model.AccountConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.Blocks.ListMuted(ctx, currentUserID, cursor, limit)
+	if err != nil {
+		log.Printf("MutedAccounts DB Error for user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to list muted accounts")
+	}
+	return accountConnection(edges, limit), nil
+}
+
+// checkOtherAccount returns a BAD_USER_INPUT or NOT_FOUND error unless accountID is an existing account other
+// than currentUserID. verb names the action in the error message.
+func (r *Resolver) checkOtherAccount(ctx context.Context, op, currentUserID, accountID, verb string) error {
+	if accountID == currentUserID {
+		return codedError(ctx, CodeBadUserInput, "cannot "+verb+" yourself")
+	}
+	if _, err := r.Accounts.GetByID(ctx, accountID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return codedError(ctx, CodeNotFound, "account not found")
+		}
+		log.Printf("%s DB Error querying account %s: %v", op, accountID, err)
+		return fmt.Errorf("internal server error")
+	}
+	return nil
+}
+
+// severFollow removes followerID's follow of, or pending request to follow, followedID, along with the
+// followed account's posts on the follower's timeline.
+func (r *Resolver) severFollow(ctx context.Context, followerID, followedID string) error {
+	removed, err := r.Follows.Unfollow(ctx, followerID, followedID)
+	if err != nil {
+		return err
+	}
+	if removed {
+		if err := r.Timelines.Prune(ctx, followerID, followedID); err != nil {
+			return err
+		}
+	}
+	_, err = r.FollowRequests.Delete(ctx, followerID, followedID)
+	return err
+}
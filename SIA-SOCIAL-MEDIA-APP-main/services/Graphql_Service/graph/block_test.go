@@ -0,0 +1,161 @@
+package graph
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+)
+
+func listedTitles(t *testing.T, c *client.Client, viewerID string) []string {
+	t.Helper()
+	var resp struct {
+		ListPosts struct {
+			Edges []struct{ Node struct{ Title string } }
+		}
+	}
+	c.MustPost(`query { listPosts(first: 50) { edges { node { title } } } }`, &resp, asUser(viewerID))
+	titles := []string{}
+	for _, e := range resp.ListPosts.Edges {
+		titles = append(titles, e.Node.Title)
+	}
+	return titles
+}
+
+func unreadCount(t *testing.T, c *client.Client, accountID string) int {
+	t.Helper()
+	var resp struct{ UnreadNotificationCount int }
+	c.MustPost(`query { unreadNotificationCount }`, &resp, asUser(accountID))
+	return resp.UnreadNotificationCount
+}
+
+func TestBlockHidesAccountsFromEachOther(t *testing.T) {
+	c, _ := newTestClient(t)
+	viewer := register(t, c, "viewer@example.com")
+	blocked := register(t, c, "blocked@example.com")
+	follow(t, c, viewer, blocked)
+	follow(t, c, blocked, viewer)
+	blockedPost := createPost(t, c, blocked, "by blocked")
+	viewerPost := createPost(t, c, viewer, "by viewer")
+	eventually(t, "the posts to fan out", func() bool { return len(feedTitles(t, c, viewer)) == 1 && len(feedTitles(t, c, blocked)) == 1 })
+
+	var resp map[string]any
+	c.MustPost(`mutation($id: ID!) { blockUser(accountId: $id) }`, &resp, client.Var("id", blocked), asUser(viewer))
+
+	for _, tt := range []struct {
+		name           string
+		viewerID, post string
+	}{
+		{"blocker", viewer, blockedPost},
+		{"blocked", blocked, viewerPost},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := feedTitles(t, c, tt.viewerID); len(got) != 0 {
+				t.Errorf("feed = %v, want it empty", got)
+			}
+			if got := listedTitles(t, c, tt.viewerID); len(got) != 1 {
+				t.Errorf("listPosts = %v, want only the viewer's own post", got)
+			}
+			var post struct{ GetPost *struct{ PostID string } }
+			c.MustPost(`query($id: ID!) { getPost(postId: $id) { postId } }`, &post, client.Var("id", tt.post), asUser(tt.viewerID))
+			if post.GetPost != nil {
+				t.Error("getPost returns the other account's post")
+			}
+			err := c.Post(`mutation($id: ID!) { reactToPost(postId: $id, reaction: LIKE) { postId } }`, &resp, client.Var("id", tt.post), asUser(tt.viewerID))
+			if errorCode(err) != CodeNotFound {
+				t.Errorf("reacting to the other account's post got %v, want %s", err, CodeNotFound)
+			}
+		})
+	}
+
+	var account struct {
+		GetAccount struct{ FollowerCount, FollowingCount int }
+	}
+	c.MustPost(`query($id: ID!) { getAccount(accountId: $id) { followerCount followingCount } }`, &account, client.Var("id", blocked), asUser(viewer))
+	if a := account.GetAccount; a.FollowerCount != 0 || a.FollowingCount != 0 {
+		t.Errorf("blocking left follows behind: %+v", a)
+	}
+	if err := c.Post(`mutation($id: ID!) { followUser(userIdToFollow: $id) { accountId } }`, &resp, client.Var("id", viewer), asUser(blocked)); err == nil {
+		t.Error("the blocked account can follow the blocker")
+	}
+	err := c.Post(`mutation($id: ID!) { followUser(userIdToFollow: $id) { accountId } }`, &resp, client.Var("id", blocked), asUser(viewer))
+	if errorCode(err) != CodeForbidden {
+		t.Errorf("the blocker following the blocked account got %v, want %s", err, CodeForbidden)
+	}
+	if err := c.Post(`query($id: ID!) { getAccount(accountId: $id) { accountId } }`, &resp, client.Var("id", viewer), asUser(blocked)); err == nil {
+		t.Error("the blocked account can look up the blocker")
+	}
+
+	var list struct {
+		BlockedAccounts struct {
+			Edges []struct{ Node struct{ AccountID string } }
+		}
+	}
+	c.MustPost(`query { blockedAccounts { edges { node { accountId } } } }`, &list, asUser(viewer))
+	if edges := list.BlockedAccounts.Edges; len(edges) != 1 || edges[0].Node.AccountID != blocked {
+		t.Errorf("blockedAccounts = %+v, want the blocked account", edges)
+	}
+
+	// Unblocking shows posts again but does not restore the follows.
+	c.MustPost(`mutation($id: ID!) { unblockUser(accountId: $id) }`, &resp, client.Var("id", blocked), asUser(viewer))
+	if got := listedTitles(t, c, viewer); len(got) != 2 {
+		t.Errorf("after unblocking, listPosts = %v, want both posts", got)
+	}
+	if got := feedTitles(t, c, viewer); len(got) != 0 {
+		t.Errorf("after unblocking, feed = %v, want it empty", got)
+	}
+}
+
+func TestMuteHidesPostsAndNotifications(t *testing.T) {
+	c, _ := newTestClient(t)
+	viewer := register(t, c, "viewer@example.com")
+	muted := register(t, c, "muted@example.com")
+	other := register(t, c, "other@example.com")
+	follow(t, c, viewer, muted)
+	follow(t, c, viewer, other)
+	mutedPost := createPost(t, c, muted, "by muted")
+	createPost(t, c, other, "by other")
+	viewerPost := createPost(t, c, viewer, "by viewer")
+	eventually(t, "the posts to fan out", func() bool { return len(feedTitles(t, c, viewer)) == 2 })
+
+	var resp map[string]any
+	c.MustPost(`mutation($id: ID!) { muteUser(accountId: $id) }`, &resp, client.Var("id", muted), asUser(viewer))
+
+	if got := feedTitles(t, c, viewer); !slices.Equal(got, []string{"by other"}) {
+		t.Errorf("feed = %v, want only the unmuted author's post", got)
+	}
+	if got := listedTitles(t, c, viewer); slices.Contains(got, "by muted") {
+		t.Errorf("listPosts = %v, want the muted author left out", got)
+	}
+	var post struct{ GetPost *struct{ PostID string } }
+	c.MustPost(`query($id: ID!) { getPost(postId: $id) { postId } }`, &post, client.Var("id", mutedPost), asUser(viewer))
+	if post.GetPost == nil {
+		t.Error("muting hides the post from getPost; only feeds should leave it out")
+	}
+
+	// The muted account can still see and react to the viewer's posts, without notifying them.
+	c.MustPost(`mutation($id: ID!) { reactToPost(postId: $id, reaction: LIKE) { postId } }`, &resp, client.Var("id", viewerPost), asUser(muted))
+	c.MustPost(`mutation($id: ID!) { reactToPost(postId: $id, reaction: LIKE) { postId } }`, &resp, client.Var("id", viewerPost), asUser(other))
+	eventually(t, "the other account's notification", func() bool { return unreadCount(t, c, viewer) >= 1 })
+	var notifications struct {
+		GetMyNotifications struct {
+			Edges []struct {
+				Node struct{ TriggeringUser struct{ AccountID string } }
+			}
+		}
+	}
+	c.MustPost(`query { getMyNotifications { edges { node { triggeringUser { accountId } } } } }`, &notifications, asUser(viewer))
+	for _, e := range notifications.GetMyNotifications.Edges {
+		if e.Node.TriggeringUser.AccountID == muted {
+			t.Error("the viewer is notified of the muted account's reaction")
+		}
+	}
+	if n := unreadCount(t, c, viewer); n != 1 {
+		t.Errorf("unreadNotificationCount = %d, want 1", n)
+	}
+
+	c.MustPost(`mutation($id: ID!) { unmuteUser(accountId: $id) }`, &resp, client.Var("id", muted), asUser(viewer))
+	if got := feedTitles(t, c, viewer); len(got) != 2 {
+		t.Errorf("after unmuting, feed = %v, want both posts back", got)
+	}
+}
@@ -0,0 +1,230 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.72
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *model.Comment) (*model.Account, error) {
+	author, err := r.dataLoaders(ctx).Accounts.Load(ctx, obj.AuthorID)
+	if err != nil {
+		log.Printf("Author DB Error loading account %s of comment %s: %v", obj.AuthorID, obj.CommentID, err)
+		return nil, fmt.Errorf("failed to fetch comment author")
+	}
+	if author == nil {
+		log.Printf("Author: account %s of comment %s does not exist", obj.AuthorID, obj.CommentID)
+		return nil, fmt.Errorf("comment author not found")
+	}
+	return author, nil
+}
+
+// Replies is the resolver for the replies field.
+func (r *commentResolver) Replies(ctx context.Context, obj *model.Comment, first *int32, after *string) (*model.CommentConnection, error) {
+	return r.listComments(ctx, obj.PostID, obj.CommentID, first, after)
+}
+
+// AddComment is the resolver for the addComment field.
+func (r *mutationResolver) AddComment(ctx context.Context, postID string, content string, parentCommentID *string) (*model.Comment, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	content, err = validateCommentContent(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.Posts.GetVisible(ctx, postID, currentUserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "post not found")
+		}
+		log.Printf("AddComment DB Error fetching post %s: %v", postID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+
+	input := store.NewComment{PostID: postID, AuthorID: currentUserID, Content: content}
+	var parent *model.Comment
+	if parentCommentID != nil {
+		parent, err = r.Comments.GetByID(ctx, *parentCommentID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			log.Printf("AddComment DB Error fetching parent comment %s: %v", *parentCommentID, err)
+			return nil, fmt.Errorf("internal server error")
+		}
+		if parent == nil || parent.PostID != postID || parent.DeletedAt != nil {
+			return nil, codedError(ctx, CodeNotFound, "parent comment not found on this post")
+		}
+		input.ParentID = parent.CommentID
+	}
+
+	comment, err := r.Comments.Create(ctx, input)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Post or parent deleted since the checks above
+			return nil, codedError(ctx, CodeNotFound, "post or parent comment not found")
+		}
+		log.Printf("AddComment DB Error inserting comment on post %s: %v", postID, err)
+		return nil, fmt.Errorf("failed to add comment")
+	}
+	log.Printf("Comment %s added to post %s by %s", comment.CommentID, postID, currentUserID)
+
+	// --- Notify the post author and the parent commenter ---
+	go func(commenterID string, postAuthorID string, parent *model.Comment, postID string) {
+		notifCtx, notifCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer notifCancel()
+		notify := func(recipientID string, notificationType model.NotificationType) {
+			r.sendNotifications(notifCtx, notificationType, commenterID, postID, []string{recipientID}, time.Now())
+		}
+		// A post author replying in their own thread is told about the reply, not about a new comment.
+		if parent != nil && parent.AuthorID != commenterID {
+			notify(parent.AuthorID, model.NotificationTypeCommentReply)
+		}
+		if postAuthorID != commenterID && (parent == nil || parent.AuthorID != postAuthorID) {
+			notify(postAuthorID, model.NotificationTypeNewComment)
+		}
+	}(currentUserID, post.AuthorID, parent, postID)
+
+	return comment, nil
+}
+
+// EditComment is the resolver for the editComment field.
+func (r *mutationResolver) EditComment(ctx context.Context, commentID string, content string) (*model.Comment, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	content, err = validateCommentContent(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := r.liveComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.AuthorID != currentUserID {
+		return nil, codedError(ctx, CodeForbidden, "only the author can edit this comment")
+	}
+
+	comment, err := r.Comments.Update(ctx, commentID, currentUserID, content)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Deleted since the check above
+			return nil, codedError(ctx, CodeNotFound, "comment not found")
+		}
+		log.Printf("EditComment DB Error updating comment %s: %v", commentID, err)
+		return nil, fmt.Errorf("failed to edit comment")
+	}
+	return comment, nil
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, commentID string) (*model.Comment, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := r.liveComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.AuthorID != currentUserID {
+		// The post's author may moderate comments on their post.
+		post, err := r.Posts.GetByID(ctx, existing.PostID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			log.Printf("DeleteComment DB Error fetching post %s: %v", existing.PostID, err)
+			return nil, fmt.Errorf("internal server error")
+		}
+		if post == nil || post.AuthorID != currentUserID {
+			return nil, codedError(ctx, CodeForbidden, "only the comment's or the post's author can delete this comment")
+		}
+	}
+
+	comment, err := r.Comments.Delete(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Deleted since the check above
+			return nil, codedError(ctx, CodeNotFound, "comment not found")
+		}
+		log.Printf("DeleteComment DB Error deleting comment %s: %v", commentID, err)
+		return nil, fmt.Errorf("failed to delete comment")
+	}
+	log.Printf("Comment %s deleted by %s", commentID, currentUserID)
+	return comment, nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *postResolver) Comments(ctx context.Context, obj *model.Post, first *int32, after *string) (*model.CommentConnection, error) {
+	return r.listComments(ctx, obj.PostID, "", first, after)
+}
+
+// CommentCount is the resolver for the commentCount field.
+func (r *postResolver) CommentCount(ctx context.Context, obj *model.Post) (int32, error) {
+	n, err := r.Repositories.Comments.CountForPost(ctx, obj.PostID) // r.Comments is the Post.comments resolver here
+	if err != nil {
+		log.Printf("CommentCount DB Error counting comments of post %s: %v", obj.PostID, err)
+		return 0, fmt.Errorf("failed to count comments")
+	}
+	return int32(n), nil
+}
+
+// Comment returns CommentResolver implementation.
+func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
+
+type commentResolver struct{ *Resolver }
+
+// maxCommentLength bounds comment content, in characters.
+const maxCommentLength = 2000
+
+// validateCommentContent trims content and rejects empty or overlong comments.
+func validateCommentContent(ctx context.Context, content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", codedError(ctx, CodeBadUserInput, "comment cannot be empty")
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		return "", codedError(ctx, CodeBadUserInput, fmt.Sprintf("comment must be at most %d characters", maxCommentLength))
+	}
+	return content, nil
+}
+
+// liveComment fetches a comment that has not been deleted, or returns a NOT_FOUND error.
+func (r *Resolver) liveComment(ctx context.Context, commentID string) (*model.Comment, error) {
+	comment, err := r.Comments.GetByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "comment not found")
+		}
+		log.Printf("liveComment DB Error fetching comment %s: %v", commentID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	if comment.DeletedAt != nil {
+		return nil, codedError(ctx, CodeNotFound, "comment not found")
+	}
+	return comment, nil
+}
+
+// listComments pages through the comments of postID under parentID ("" for top-level comments).
+func (r *Resolver) listComments(ctx context.Context, postID, parentID string, first *int32, after *string) (*model.CommentConnection, error) {
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.Comments.List(ctx, postID, parentID, cursor, limit)
+	if err != nil {
+		log.Printf("listComments DB Error querying comments of post %s (parent %q): %v", postID, parentID, err)
+		return nil, fmt.Errorf("failed to fetch comments")
+	}
+	edges, pageInfo := trimPage(edges, limit, func(e *model.CommentEdge) string { return e.Cursor })
+	return &model.CommentConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
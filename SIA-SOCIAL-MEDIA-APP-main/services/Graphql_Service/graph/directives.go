@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"graphql/graph/model"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// NewConfig wires the resolver and the schema directive implementations into a gqlgen Config.
+func NewConfig(r *Resolver) Config {
+	c := Config{Resolvers: r}
+	c.Directives.Private = privateDirective
+	c.Directives.Auth = authDirective
+	c.Directives.HasRole = hasRoleDirective
+	return c
+}
+
+// authDirective implements @auth: anonymous callers get UNAUTHENTICATED instead of the field.
+func authDirective(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	if _, err := getCurrentUserID(ctx); err != nil {
+		return nil, codedError(ctx, CodeUnauthenticated, "authentication required")
+	}
+	return next(ctx)
+}
+
+// hasRoleDirective implements @hasRole: the caller must be authenticated and hold role.
+func hasRoleDirective(ctx context.Context, obj interface{}, next graphql.Resolver, role model.Role) (interface{}, error) {
+	if _, err := getCurrentUserID(ctx); err != nil {
+		return nil, codedError(ctx, CodeUnauthenticated, "authentication required")
+	}
+	if !currentUserHasRole(ctx, role) {
+		return nil, codedError(ctx, CodeForbidden, "requires the "+strings.ToLower(role.String())+" role")
+	}
+	return next(ctx)
+}
+
+// privateDirective implements @private: the field resolves only for the owner of obj or an admin,
+// everyone else sees null.
+func privateDirective(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	viewerID, err := getCurrentUserID(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	if viewerID == ownerID(obj) || currentUserHasRole(ctx, model.RoleAdmin) {
+		return next(ctx)
+	}
+	return nil, nil
+}
+
+// ownerID returns the account that owns a schema object, or "" when it has no owner.
+func ownerID(obj interface{}) string {
+	switch o := obj.(type) {
+	case *model.Account:
+		return o.AccountID
+	case *model.Profile:
+		return o.ProfileID
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,28 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Error codes returned in the "extensions.code" field of GraphQL errors, so clients can branch on them
+// without parsing messages.
+const (
+	CodeUsernameTaken   = "USERNAME_TAKEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeBadUserInput    = "BAD_USER_INPUT"
+	CodeConflict        = "CONFLICT"
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+)
+
+// codedError builds a GraphQL error for the current field carrying a machine-readable code.
+func codedError(ctx context.Context, code string, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Path:       graphql.GetPath(ctx),
+		Message:    message,
+		Extensions: map[string]interface{}{"code": code},
+	}
+}
@@ -0,0 +1,447 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.72
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Sender is the resolver for the sender field.
+func (r *messageResolver) Sender(ctx context.Context, obj *model.Message) (*model.Account, error) {
+	sender, err := r.dataLoaders(ctx).Accounts.Load(ctx, obj.SenderID)
+	if err != nil {
+		log.Printf("Sender DB Error loading account %s of message %s: %v", obj.SenderID, obj.MessageID, err)
+		return nil, fmt.Errorf("failed to fetch message sender")
+	}
+	if sender == nil {
+		log.Printf("Sender: account %s of message %s does not exist", obj.SenderID, obj.MessageID)
+		return nil, fmt.Errorf("message sender not found")
+	}
+	return sender, nil
+}
+
+// StartConversation is the resolver for the startConversation field.
+func (r *mutationResolver) StartConversation(ctx context.Context, memberIds []string, title *string) (*model.Conversation, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	others := []string{}
+	seen := map[string]bool{}
+	for _, id := range memberIds {
+		if id == currentUserID {
+			return nil, codedError(ctx, CodeBadUserInput, "memberIds must not include yourself")
+		}
+		if !seen[id] {
+			seen[id] = true
+			others = append(others, id)
+		}
+	}
+	if len(others) == 0 {
+		return nil, codedError(ctx, CodeBadUserInput, "add at least one other member")
+	}
+	if len(others)+1 > maxConversationMembers {
+		return nil, codedError(ctx, CodeBadUserInput, fmt.Sprintf("conversations have at most %d members", maxConversationMembers))
+	}
+	if title != nil {
+		trimmed := strings.TrimSpace(*title)
+		if utf8.RuneCountInString(trimmed) > maxConversationTitleLength {
+			return nil, codedError(ctx, CodeBadUserInput, fmt.Sprintf("title must be at most %d characters", maxConversationTitleLength))
+		}
+		title = &trimmed
+		if trimmed == "" {
+			title = nil
+		}
+	}
+
+	accounts, err := r.Accounts.GetByIDs(ctx, others)
+	if err != nil {
+		log.Printf("StartConversation DB Error querying members: %v", err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	for _, id := range others {
+		if accounts[id] == nil {
+			return nil, codedError(ctx, CodeNotFound, "account not found")
+		}
+		if err := r.checkCanMessage(ctx, "StartConversation", currentUserID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	conv, err := r.Messages.CreateConversation(ctx, store.NewConversation{
+		MemberIDs: append([]string{currentUserID}, others...),
+		Title:     title,
+		IsGroup:   len(others) > 1 || title != nil,
+	})
+	if err != nil {
+		log.Printf("StartConversation DB Error for user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to start conversation")
+	}
+	if err := r.fillConversations(ctx, currentUserID, conv); err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+// SendMessage is the resolver for the sendMessage field.
+func (r *mutationResolver) SendMessage(ctx context.Context, conversationID string, body string) (*model.Message, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	body, err = validateMessageBody(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	conv, err := r.memberConversation(ctx, currentUserID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !conv.IsGroup {
+		members, err := r.Messages.Members(ctx, []string{conversationID})
+		if err != nil {
+			log.Printf("SendMessage DB Error querying members of %s: %v", conversationID, err)
+			return nil, fmt.Errorf("internal server error")
+		}
+		for _, m := range members[conversationID] {
+			if m.AccountID == currentUserID {
+				continue
+			}
+			blocked, err := r.blockedEitherWay(ctx, currentUserID, m.AccountID)
+			if err != nil {
+				log.Printf("SendMessage DB Error checking blocks (%s, %s): %v", currentUserID, m.AccountID, err)
+				return nil, fmt.Errorf("internal server error")
+			}
+			if blocked {
+				return nil, codedError(ctx, CodeForbidden, "cannot message this account while either of you blocks the other")
+			}
+		}
+	}
+
+	msg, err := r.Messages.Send(ctx, conversationID, currentUserID, body)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Left or deleted since the membership check
+			return nil, codedError(ctx, CodeNotFound, "conversation not found")
+		}
+		log.Printf("SendMessage DB Error in conversation %s: %v", conversationID, err)
+		return nil, fmt.Errorf("failed to send message")
+	}
+	return msg, nil
+}
+
+// EditMessage is the resolver for the editMessage field.
+func (r *mutationResolver) EditMessage(ctx context.Context, messageID string, body string) (*model.Message, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	body, err = validateMessageBody(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := r.checkMessageSender(ctx, currentUserID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.DeletedAt != nil {
+		return nil, codedError(ctx, CodeNotFound, "message not found")
+	}
+
+	msg, err = r.Messages.Edit(ctx, messageID, currentUserID, body)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Deleted since the sender check
+			return nil, codedError(ctx, CodeNotFound, "message not found")
+		}
+		log.Printf("EditMessage DB Error editing message %s: %v", messageID, err)
+		return nil, fmt.Errorf("failed to edit message")
+	}
+	return msg, nil
+}
+
+// DeleteMessage is the resolver for the deleteMessage field.
+func (r *mutationResolver) DeleteMessage(ctx context.Context, messageID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	msg, err := r.checkMessageSender(ctx, currentUserID, messageID)
+	if err != nil {
+		return false, err
+	}
+	if msg.DeletedAt != nil {
+		return false, nil
+	}
+
+	if _, err := r.Messages.Delete(ctx, messageID, currentUserID); err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Deleted since the sender check
+			return false, nil
+		}
+		log.Printf("DeleteMessage DB Error deleting message %s: %v", messageID, err)
+		return false, fmt.Errorf("failed to delete message")
+	}
+	return true, nil
+}
+
+// MarkConversationRead is the resolver for the markConversationRead field.
+func (r *mutationResolver) MarkConversationRead(ctx context.Context, conversationID string, messageID *string) (*model.Conversation, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	conv, err := r.memberConversation(ctx, currentUserID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	upTo := ""
+	if messageID != nil {
+		upTo = *messageID
+	}
+	if err := r.Messages.MarkRead(ctx, conversationID, currentUserID, upTo); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "message not found")
+		}
+		log.Printf("MarkConversationRead DB Error for user %s in %s: %v", currentUserID, conversationID, err)
+		return nil, fmt.Errorf("failed to mark conversation read")
+	}
+	if err := r.fillConversations(ctx, currentUserID, conv); err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+// Conversations is the resolver for the conversations field.
+func (r *queryResolver) Conversations(ctx context.Context, first *int32, after *string) (*model.ConversationConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.Repositories.Messages.ListConversations(ctx, currentUserID, cursor, limit)
+	if err != nil {
+		log.Printf("Conversations DB Error for user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to list conversations")
+	}
+	edges, pageInfo := trimPage(edges, limit, func(e *model.ConversationEdge) string { return e.Cursor })
+
+	convs := make([]*model.Conversation, len(edges))
+	for i, e := range edges {
+		convs[i] = e.Node
+	}
+	if err := r.fillConversations(ctx, currentUserID, convs...); err != nil {
+		return nil, err
+	}
+	return &model.ConversationConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
+
+// Conversation is the resolver for the conversation field.
+func (r *queryResolver) Conversation(ctx context.Context, conversationID string) (*model.Conversation, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	conv, err := r.Repositories.Messages.GetConversation(ctx, conversationID, currentUserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil // Also for conversations the user is not in
+		}
+		log.Printf("Conversation DB Error fetching %s: %v", conversationID, err)
+		return nil, fmt.Errorf("failed to fetch conversation")
+	}
+	if err := r.fillConversations(ctx, currentUserID, conv); err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+// Messages is the resolver for the messages field.
+func (r *queryResolver) Messages(ctx context.Context, conversationID string, first *int32, before *string) (*model.MessageConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	limit, cursor, err := pageArgs(ctx, first, before)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.memberConversation(ctx, currentUserID, conversationID); err != nil {
+		return nil, err
+	}
+	edges, err := r.Repositories.Messages.ListMessages(ctx, conversationID, currentUserID, cursor, limit)
+	if err != nil {
+		log.Printf("Messages DB Error for conversation %s: %v", conversationID, err)
+		return nil, fmt.Errorf("failed to list messages")
+	}
+	edges, pageInfo := trimPage(edges, limit, func(e *model.MessageEdge) string { return e.Cursor })
+	return &model.MessageConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
+
+// MessageReceived is the resolver for the messageReceived field.
+func (r *subscriptionResolver) MessageReceived(ctx context.Context) (<-chan *model.Message, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	// ctx ends when the client stops the subscription or the socket closes, which unsubscribes.
+	ch, err := r.Repositories.Messages.Subscribe(ctx, currentUserID)
+	if err != nil {
+		log.Printf("MessageReceived: Failed to subscribe user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to subscribe to messages")
+	}
+	log.Printf("MessageReceived: User %s subscribed", currentUserID)
+	return ch, nil
+}
+
+// Message returns MessageResolver implementation.
+func (r *Resolver) Message() MessageResolver { return &messageResolver{r} }
+
+type messageResolver struct{ *Resolver }
+
+// Conversation limits. The member limit counts the creator.
+const (
+	maxConversationMembers     = 10
+	maxConversationTitleLength = 100
+	maxMessageLength           = 4000
+)
+
+// validateMessageBody trims body and rejects empty or overlong messages.
+func validateMessageBody(ctx context.Context, body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", codedError(ctx, CodeBadUserInput, "message cannot be empty")
+	}
+	if utf8.RuneCountInString(body) > maxMessageLength {
+		return "", codedError(ctx, CodeBadUserInput, fmt.Sprintf("message must be at most %d characters", maxMessageLength))
+	}
+	return body, nil
+}
+
+// blockedEitherWay reports whether a blocks b or b blocks a.
+func (r *Resolver) blockedEitherWay(ctx context.Context, a, b string) (bool, error) {
+	blocking, err := r.Blocks.IsBlocking(ctx, a, b)
+	if err != nil || blocking {
+		return blocking, err
+	}
+	return r.Blocks.IsBlocking(ctx, b, a)
+}
+
+// checkCanMessage returns an error unless currentUserID may add accountID to a conversation. As with follows,
+// an account that blocks the user looks like it does not exist.
+func (r *Resolver) checkCanMessage(ctx context.Context, op, currentUserID, accountID string) error {
+	blockedBy, err := r.Blocks.IsBlocking(ctx, accountID, currentUserID)
+	if err != nil {
+		log.Printf("%s DB Error checking block (%s -> %s): %v", op, accountID, currentUserID, err)
+		return fmt.Errorf("internal server error")
+	}
+	if blockedBy {
+		return codedError(ctx, CodeNotFound, "account not found")
+	}
+	blocking, err := r.Blocks.IsBlocking(ctx, currentUserID, accountID)
+	if err != nil {
+		log.Printf("%s DB Error checking block (%s -> %s): %v", op, currentUserID, accountID, err)
+		return fmt.Errorf("internal server error")
+	}
+	if blocking {
+		return codedError(ctx, CodeForbidden, "unblock this account before messaging it")
+	}
+	return nil
+}
+
+// memberConversation fetches a conversation of userID, or returns a NOT_FOUND error.
+func (r *Resolver) memberConversation(ctx context.Context, userID, conversationID string) (*model.Conversation, error) {
+	conv, err := r.Messages.GetConversation(ctx, conversationID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "conversation not found")
+		}
+		log.Printf("memberConversation DB Error fetching %s: %v", conversationID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	return conv, nil
+}
+
+// checkMessageSender fetches a message userID can see and returns a NOT_FOUND or FORBIDDEN error unless userID
+// sent it. Deleted messages are returned too.
+func (r *Resolver) checkMessageSender(ctx context.Context, userID, messageID string) (*model.Message, error) {
+	msg, err := r.Messages.GetMessage(ctx, messageID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "message not found")
+		}
+		log.Printf("checkMessageSender DB Error fetching message %s: %v", messageID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	if msg.SenderID != userID {
+		return nil, codedError(ctx, CodeForbidden, "only the sender can change this message")
+	}
+	return msg, nil
+}
+
+// fillConversations sets the members, last message and unread count of convs as seen by viewerID, with one
+// query each for the whole page.
+func (r *Resolver) fillConversations(ctx context.Context, viewerID string, convs ...*model.Conversation) error {
+	if len(convs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(convs))
+	for i, c := range convs {
+		ids[i] = c.ConversationID
+	}
+	members, err := r.Messages.Members(ctx, ids)
+	if err != nil {
+		log.Printf("fillConversations DB Error querying members: %v", err)
+		return fmt.Errorf("failed to fetch conversations")
+	}
+	var accountIDs []string
+	for _, ms := range members {
+		for _, m := range ms {
+			accountIDs = append(accountIDs, m.AccountID)
+		}
+	}
+	accounts, err := r.Accounts.GetByIDs(ctx, accountIDs)
+	if err != nil {
+		log.Printf("fillConversations DB Error querying member accounts: %v", err)
+		return fmt.Errorf("failed to fetch conversations")
+	}
+	lastMessages, err := r.Messages.LastMessages(ctx, viewerID, ids)
+	if err != nil {
+		log.Printf("fillConversations DB Error querying last messages: %v", err)
+		return fmt.Errorf("failed to fetch conversations")
+	}
+	unread, err := r.Messages.UnreadCounts(ctx, viewerID, ids)
+	if err != nil {
+		log.Printf("fillConversations DB Error counting unread messages of %s: %v", viewerID, err)
+		return fmt.Errorf("failed to fetch conversations")
+	}
+
+	for _, c := range convs {
+		c.Members = []*model.ConversationMember{}
+		for _, m := range members[c.ConversationID] {
+			account := accounts[m.AccountID]
+			if account == nil {
+				continue // Deleted between the two queries
+			}
+			member := &model.ConversationMember{Account: account}
+			if m.LastReadMessageID != "" {
+				lastReadAt := m.LastReadAt.Format(time.RFC3339)
+				member.LastReadMessageID, member.LastReadAt = &m.LastReadMessageID, &lastReadAt
+			}
+			c.Members = append(c.Members, member)
+		}
+		c.LastMessage = lastMessages[c.ConversationID]
+		c.UnreadCount = int32(unread[c.ConversationID])
+	}
+	return nil
+}
@@ -2,30 +2,135 @@
 
 package model
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
 type Account struct {
-	AccountID   string  `json:"accountId"`
-	Email       string  `json:"email"`
-	FirstName   string  `json:"firstName"`
-	LastName    string  `json:"lastName"`
-	Address     *string `json:"address,omitempty"`
-	Phone       *string `json:"phone,omitempty"`
-	Age         int32   `json:"age"`
-	Gender      *string `json:"gender,omitempty"`
-	IsFollowing *bool   `json:"isFollowing,omitempty"`
-	CreatedAt   string  `json:"createdAt"`
-	UpdatedAt   *string `json:"updatedAt,omitempty"`
+	AccountID string  `json:"accountId"`
+	Email     *string `json:"email,omitempty"`
+	FirstName string  `json:"firstName"`
+	LastName  string  `json:"lastName"`
+	Address   *string `json:"address,omitempty"`
+	Phone     *string `json:"phone,omitempty"`
+	Age       *int32  `json:"age,omitempty"`
+	Gender    *string `json:"gender,omitempty"`
+	// Private accounts approve each follower, and only show their posts to followers.
+	IsPrivate   bool  `json:"isPrivate"`
+	IsFollowing *bool `json:"isFollowing,omitempty"`
+	// Whether the logged-in user asked to follow this private account and is waiting for approval.
+	FollowRequested bool `json:"followRequested"`
+	// Whether this account follows the logged-in user; false for anonymous requests and for yourself.
+	FollowsYou bool `json:"followsYou"`
+	// Accounts following this one, most recent follow first.
+	Followers *AccountConnection `json:"followers"`
+	// Accounts this one follows, most recent follow first.
+	Following      *AccountConnection `json:"following"`
+	FollowerCount  int32              `json:"followerCount"`
+	FollowingCount int32              `json:"followingCount"`
+	CreatedAt      string             `json:"createdAt"`
+	UpdatedAt      *string            `json:"updatedAt,omitempty"`
+}
+
+type AccountConnection struct {
+	Edges    []*AccountEdge `json:"edges"`
+	PageInfo *PageInfo      `json:"pageInfo"`
+}
+
+type AccountEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Account `json:"node"`
+}
+
+// A session issued by this service. Send accessToken as "Authorization: Bearer <token>";
+// exchange refreshToken for a new pair before accessTokenExpiresAt.
+type AuthPayload struct {
+	AccessToken          string `json:"accessToken"`
+	AccessTokenExpiresAt string `json:"accessTokenExpiresAt"`
+	// Single use: every refreshToken call revokes it and returns a new one.
+	RefreshToken          string   `json:"refreshToken"`
+	RefreshTokenExpiresAt string   `json:"refreshTokenExpiresAt"`
+	Account               *Account `json:"account"`
+}
+
+// A comment on a post, or a reply to another comment of the same post.
+// Deleted comments keep their place in the thread so their replies stay attached:
+// their content is empty and deletedAt is set.
+type Comment struct {
+	CommentID       string   `json:"commentId"`
+	PostID          string   `json:"postId"`
+	ParentCommentID *string  `json:"parentCommentId,omitempty"`
+	AuthorID        string   `json:"authorId"`
+	Author          *Account `json:"author"`
+	Content         string   `json:"content"`
+	CreatedAt       string   `json:"createdAt"`
+	UpdatedAt       *string  `json:"updatedAt,omitempty"`
+	DeletedAt       *string  `json:"deletedAt,omitempty"`
+	// Direct replies, oldest first.
+	Replies         *CommentConnection `json:"replies"`
+	ReactionSummary *ReactionSummary   `json:"reactionSummary"`
+	// The logged-in account's reaction, or null.
+	MyReaction *ReactionType `json:"myReaction,omitempty"`
+}
+
+type CommentConnection struct {
+	Edges    []*CommentEdge `json:"edges"`
+	PageInfo *PageInfo      `json:"pageInfo"`
+}
+
+type CommentEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Comment `json:"node"`
+}
+
+// A private conversation: direct between two accounts, or a small group.
+type Conversation struct {
+	ConversationID string `json:"conversationId"`
+	// Group conversations have two to ten members and may have a title; direct ones have exactly two and none.
+	IsGroup bool    `json:"isGroup"`
+	Title   *string `json:"title,omitempty"`
+	// Every member, including the logged-in user, with their read positions.
+	Members []*ConversationMember `json:"members"`
+	// The newest message the logged-in user can see, or null.
+	LastMessage *Message `json:"lastMessage,omitempty"`
+	// Messages from other members newer than the logged-in user's read position.
+	UnreadCount   int32  `json:"unreadCount"`
+	CreatedAt     string `json:"createdAt"`
+	LastMessageAt string `json:"lastMessageAt"`
+}
+
+type ConversationConnection struct {
+	Edges    []*ConversationEdge `json:"edges"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+type ConversationEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *Conversation `json:"node"`
+}
+
+// A member of a conversation and how far they have read it (read receipts).
+type ConversationMember struct {
+	Account *Account `json:"account"`
+	// The newest message this member has read, or null if they have read none.
+	LastReadMessageID *string `json:"lastReadMessageId,omitempty"`
+	LastReadAt        *string `json:"lastReadAt,omitempty"`
 }
 
 type CreatePostInput struct {
-	Title    string `json:"title"`
-	Content  string `json:"content"`
-	AuthorID string `json:"authorId"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	// The author is always the logged-in account. A value other than the caller's own ID is rejected
+	// with FORBIDDEN; the field is kept only so existing clients keep working.
+	AuthorID *string `json:"authorId,omitempty"`
 }
 
+// The profile's email is always the logged-in account's.
 type CreateProfileInput struct {
 	Username          string  `json:"username"`
-	Email             string  `json:"email"`
-	Password          string  `json:"password"`
 	FirstName         *string `json:"firstName,omitempty"`
 	MiddleName        *string `json:"middleName,omitempty"`
 	LastName          *string `json:"lastName,omitempty"`
@@ -36,6 +141,44 @@ type CreateProfileInput struct {
 	Address           *string `json:"address,omitempty"`
 }
 
+// A pending request to follow the logged-in user's private account.
+type FollowRequest struct {
+	Requester *Account `json:"requester"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+type FollowRequestConnection struct {
+	Edges    []*FollowRequestEdge `json:"edges"`
+	PageInfo *PageInfo            `json:"pageInfo"`
+}
+
+type FollowRequestEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   *FollowRequest `json:"node"`
+}
+
+type Message struct {
+	MessageID      string   `json:"messageId"`
+	ConversationID string   `json:"conversationId"`
+	SenderID       string   `json:"senderId"`
+	Sender         *Account `json:"sender"`
+	// Null once the message is deleted; deleted messages stay in the history as placeholders.
+	Body      *string `json:"body,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+	EditedAt  *string `json:"editedAt,omitempty"`
+	DeletedAt *string `json:"deletedAt,omitempty"`
+}
+
+type MessageConnection struct {
+	Edges    []*MessageEdge `json:"edges"`
+	PageInfo *PageInfo      `json:"pageInfo"`
+}
+
+type MessageEdge struct {
+	Cursor string   `json:"cursor"`
+	Node   *Message `json:"node"`
+}
+
 type Mutation struct {
 }
 
@@ -46,15 +189,107 @@ type NewTodo struct {
 
 // Represents a notification for a user.
 type Notification struct {
-	NotificationID   string   `json:"notificationId"`
-	RecipientUserID  string   `json:"recipientUserId"`
-	TriggeringUser   *Account `json:"triggeringUser,omitempty"`
-	NotificationType string   `json:"notificationType"`
-	EntityID         *string  `json:"entityId,omitempty"`
-	IsRead           bool     `json:"isRead"`
-	CreatedAt        string   `json:"createdAt"`
-	// The post associated with this notification, if applicable (e.g., for 'new_post', 'like', 'new_comment').
-	Post *Post `json:"post,omitempty"`
+	NotificationID   string           `json:"notificationId"`
+	RecipientUserID  string           `json:"recipientUserId"`
+	TriggeringUser   *Account         `json:"triggeringUser,omitempty"`
+	NotificationType NotificationType `json:"notificationType"`
+	EntityID         *string          `json:"entityId,omitempty"`
+	IsRead           bool             `json:"isRead"`
+	CreatedAt        string           `json:"createdAt"`
+}
+
+type NotificationConnection struct {
+	Edges    []*NotificationEdge `json:"edges"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+type NotificationEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *Notification `json:"node"`
+}
+
+// Narrows getMyNotifications. Omitted fields do not restrict the result.
+type NotificationFilter struct {
+	// Only notifications of these types.
+	Types []NotificationType `json:"types,omitempty"`
+	// Only read (true) or only unread (false) notifications.
+	IsRead *bool `json:"isRead,omitempty"`
+	// Only notifications created at or after this RFC 3339 time.
+	CreatedAfter *string `json:"createdAfter,omitempty"`
+	// Only notifications created before this RFC 3339 time.
+	CreatedBefore *string `json:"createdBefore,omitempty"`
+}
+
+// Notifications of one type about one entity within one time window, e.g. every like of a post on one day,
+// to render as "Alice and 4 others liked your post". NEW_FOLLOWER and FOLLOW_REQUEST groups span all accounts and have no entityId.
+type NotificationGroup struct {
+	// Opaque, stable ID of the group.
+	GroupID          string           `json:"groupId"`
+	NotificationType NotificationType `json:"notificationType"`
+	EntityID         *string          `json:"entityId,omitempty"`
+	// Up to three of the most recent distinct actors, newest first.
+	Actors []*Account `json:"actors"`
+	// Number of distinct actors in the group.
+	ActorCount        int32 `json:"actorCount"`
+	NotificationCount int32 `json:"notificationCount"`
+	UnreadCount       int32 `json:"unreadCount"`
+	// Creation time of the group's newest notification.
+	LatestAt string `json:"latestAt"`
+	// The group's individual notifications, newest first, regardless of the filter the group was listed with.
+	Notifications *NotificationConnection `json:"notifications"`
+}
+
+type NotificationGroupConnection struct {
+	Edges    []*NotificationGroupEdge `json:"edges"`
+	PageInfo *PageInfo                `json:"pageInfo"`
+}
+
+type NotificationGroupEdge struct {
+	Cursor string             `json:"cursor"`
+	Node   *NotificationGroup `json:"node"`
+}
+
+type NotificationPreferences struct {
+	// One entry per notification type, in NotificationType order. Unconfigured types are in-app only.
+	Types      []*NotificationTypePreference `json:"types"`
+	WebhookURL *string                       `json:"webhookUrl,omitempty"`
+	// Authors whose new posts do not notify the logged-in user, most recently muted first.
+	MutedAuthors []*Account `json:"mutedAuthors"`
+}
+
+type NotificationPreferencesInput struct {
+	Types []*NotificationTypePreferenceInput `json:"types,omitempty"`
+	// An http(s) URL receiving webhook notifications. An empty string removes it; omit it to keep the current one.
+	WebhookURL *string `json:"webhookUrl,omitempty"`
+}
+
+// How the logged-in user receives one type of notification.
+type NotificationTypePreference struct {
+	NotificationType NotificationType `json:"notificationType"`
+	// Shown in the app, counted as unread and pushed over subscriptions.
+	InApp bool `json:"inApp"`
+	// Sent to the account's email address.
+	Email bool `json:"email"`
+	// POSTed to webhookUrl; has no effect while no URL is set.
+	Webhook bool `json:"webhook"`
+}
+
+// Changes the channels of one notification type. Omitted channels keep their current setting.
+type NotificationTypePreferenceInput struct {
+	NotificationType NotificationType `json:"notificationType"`
+	InApp            *bool            `json:"inApp,omitempty"`
+	Email            *bool            `json:"email,omitempty"`
+	Webhook          *bool            `json:"webhook,omitempty"`
+}
+
+// Pagination details of a connection, following the Relay cursor connections spec.
+// Cursors are opaque; pass endCursor as after to fetch the next page. Lists are ordered by creation time
+// and paged by keyset, so rows created while paging never shift or repeat items.
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
 }
 
 type Post struct {
@@ -65,13 +300,44 @@ type Post struct {
 	Author    *Account `json:"author"`
 	CreatedAt string   `json:"createdAt"`
 	UpdatedAt *string  `json:"updatedAt,omitempty"`
+	// Set only on the post returned by deletePost; deleted posts are hidden everywhere else.
+	DeletedAt *string `json:"deletedAt,omitempty"`
+	// Earlier versions of the post, newest first. Empty if it was never edited.
+	Revisions []*PostRevision `json:"revisions"`
+	// Top-level comments, oldest first. Replies are reached through Comment.replies.
+	Comments *CommentConnection `json:"comments"`
+	// Number of comments and replies that are not deleted.
+	CommentCount    int32            `json:"commentCount"`
+	ReactionSummary *ReactionSummary `json:"reactionSummary"`
+	// The logged-in account's reaction, or null.
+	MyReaction *ReactionType `json:"myReaction,omitempty"`
+}
+
+type PostConnection struct {
+	Edges    []*PostEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type PostEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Post  `json:"node"`
+}
+
+// A version of a post that was replaced by an edit.
+type PostRevision struct {
+	RevisionID string `json:"revisionId"`
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+	// When this version was written (the post's creation or a previous edit).
+	CreatedAt string `json:"createdAt"`
+	// When an edit replaced this version.
+	ReplacedAt string `json:"replacedAt"`
 }
 
 type Profile struct {
 	ProfileID         string  `json:"profileId"`
 	Username          string  `json:"username"`
-	Email             string  `json:"email"`
-	Password          string  `json:"password"`
+	Email             *string `json:"email,omitempty"`
 	FirstName         *string `json:"firstName,omitempty"`
 	MiddleName        *string `json:"middleName,omitempty"`
 	LastName          *string `json:"lastName,omitempty"`
@@ -85,6 +351,17 @@ type Profile struct {
 type Query struct {
 }
 
+type ReactionCount struct {
+	Reaction ReactionType `json:"reaction"`
+	Count    int32        `json:"count"`
+}
+
+// Aggregated reactions of a post or comment. counts lists only reactions used at least once, in enum order.
+type ReactionSummary struct {
+	Total  int32            `json:"total"`
+	Counts []*ReactionCount `json:"counts"`
+}
+
 type RegisterInput struct {
 	Email     string  `json:"email"`
 	Password  string  `json:"password"`
@@ -96,6 +373,18 @@ type RegisterInput struct {
 	Gender    *string `json:"gender,omitempty"`
 }
 
+type Subscription struct {
+}
+
+// An account the logged-in user might want to follow, found through the accounts they follow.
+type SuggestedAccount struct {
+	Account *Account `json:"account"`
+	// How many accounts the logged-in user follows also follow this one.
+	MutualCount int32 `json:"mutualCount"`
+	// Up to three of those accounts, most recent follow first.
+	MutualFollowers []*Account `json:"mutualFollowers"`
+}
+
 type Todo struct {
 	ID   string `json:"id"`
 	Text string `json:"text"`
@@ -103,7 +392,212 @@ type Todo struct {
 	User *User  `json:"user"`
 }
 
+// Fields the owner may change on their profile. Omitted fields are left untouched;
+// an empty string clears an optional field.
+type UpdateProfileInput struct {
+	Username          *string `json:"username,omitempty"`
+	MiddleName        *string `json:"middleName,omitempty"`
+	Bio               *string `json:"bio,omitempty"`
+	ProfilePictureURL *string `json:"profilePictureUrl,omitempty"`
+	BannerPictureURL  *string `json:"bannerPictureUrl,omitempty"`
+	DateOfBirth       *string `json:"dateOfBirth,omitempty"`
+	Address           *string `json:"address,omitempty"`
+}
+
 type User struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 }
+
+// What a notification is about, and what its entityId refers to.
+type NotificationType string
+
+const (
+	// Someone you follow published a post. entityId is the post.
+	NotificationTypeNewPost NotificationType = "NEW_POST"
+	// Someone commented on your post. entityId is the post.
+	NotificationTypeNewComment NotificationType = "NEW_COMMENT"
+	// Someone replied to your comment. entityId is the post.
+	NotificationTypeCommentReply NotificationType = "COMMENT_REPLY"
+	// Someone reacted to your post. entityId is the post.
+	NotificationTypeLike NotificationType = "LIKE"
+	// Someone followed you. entityId is the follower's account.
+	NotificationTypeNewFollower NotificationType = "NEW_FOLLOWER"
+	// Someone asked to follow your private account. entityId is the requester's account.
+	NotificationTypeFollowRequest NotificationType = "FOLLOW_REQUEST"
+)
+
+var AllNotificationType = []NotificationType{
+	NotificationTypeNewPost,
+	NotificationTypeNewComment,
+	NotificationTypeCommentReply,
+	NotificationTypeLike,
+	NotificationTypeNewFollower,
+	NotificationTypeFollowRequest,
+}
+
+func (e NotificationType) IsValid() bool {
+	switch e {
+	case NotificationTypeNewPost, NotificationTypeNewComment, NotificationTypeCommentReply, NotificationTypeLike, NotificationTypeNewFollower, NotificationTypeFollowRequest:
+		return true
+	}
+	return false
+}
+
+func (e NotificationType) String() string {
+	return string(e)
+}
+
+func (e *NotificationType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = NotificationType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid NotificationType", str)
+	}
+	return nil
+}
+
+func (e NotificationType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *NotificationType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e NotificationType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+// The fixed set of reactions. LIKE is the classic like.
+type ReactionType string
+
+const (
+	// 👍
+	ReactionTypeLike ReactionType = "LIKE"
+	// ❤️
+	ReactionTypeLove ReactionType = "LOVE"
+	// 😂
+	ReactionTypeHaha ReactionType = "HAHA"
+	// 😮
+	ReactionTypeWow ReactionType = "WOW"
+	// 😢
+	ReactionTypeSad ReactionType = "SAD"
+	// 😡
+	ReactionTypeAngry ReactionType = "ANGRY"
+)
+
+var AllReactionType = []ReactionType{
+	ReactionTypeLike,
+	ReactionTypeLove,
+	ReactionTypeHaha,
+	ReactionTypeWow,
+	ReactionTypeSad,
+	ReactionTypeAngry,
+}
+
+func (e ReactionType) IsValid() bool {
+	switch e {
+	case ReactionTypeLike, ReactionTypeLove, ReactionTypeHaha, ReactionTypeWow, ReactionTypeSad, ReactionTypeAngry:
+		return true
+	}
+	return false
+}
+
+func (e ReactionType) String() string {
+	return string(e)
+}
+
+func (e *ReactionType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReactionType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReactionType", str)
+	}
+	return nil
+}
+
+func (e ReactionType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ReactionType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ReactionType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Role string
+
+const (
+	RoleAdmin Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Role) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Role) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
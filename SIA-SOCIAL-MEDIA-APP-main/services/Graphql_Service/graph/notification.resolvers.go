@@ -2,185 +2,539 @@ package graph
 
 import (
 	"context"
-	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"graphql/graph/model" // Ensure this path is correct
+	"graphql/store"
 	"log"
-	"strings"
+	"net/url"
 	"time"
-
-	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
+// MarkNotificationsRead is the resolver for the markNotificationsRead field.
+func (r *mutationResolver) MarkNotificationsRead(ctx context.Context, ids []string) (int32, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) > maxPageSize {
+		return 0, codedError(ctx, CodeBadUserInput, fmt.Sprintf("at most %d notifications can be marked at once", maxPageSize))
+	}
+	if err := r.Notifications.MarkRead(ctx, currentUserID, ids); err != nil {
+		log.Printf("MarkNotificationsRead DB Error for user %s: %v", currentUserID, err)
+		return 0, fmt.Errorf("failed to mark notifications read")
+	}
+	return r.unreadCount(ctx, currentUserID)
+}
+
+// MarkAllNotificationsRead is the resolver for the markAllNotificationsRead field.
+func (r *mutationResolver) MarkAllNotificationsRead(ctx context.Context, before *string) (int32, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now()
+	if before != nil {
+		if cutoff, err = parseTimestamp(ctx, "before", *before); err != nil {
+			return 0, err
+		}
+	}
+	if err := r.Notifications.MarkAllRead(ctx, currentUserID, cutoff); err != nil {
+		log.Printf("MarkAllNotificationsRead DB Error for user %s: %v", currentUserID, err)
+		return 0, fmt.Errorf("failed to mark notifications read")
+	}
+	return r.unreadCount(ctx, currentUserID)
+}
+
+// UpdateNotificationPreferences is the resolver for the updateNotificationPreferences field.
+func (r *mutationResolver) UpdateNotificationPreferences(ctx context.Context, input model.NotificationPreferencesInput) (*model.NotificationPreferences, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	if input.WebhookURL != nil && *input.WebhookURL != "" {
+		u, err := url.Parse(*input.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, codedError(ctx, CodeBadUserInput, "webhookUrl must be an absolute http or https URL")
+		}
+	}
+
+	current, err := r.NotificationPreferences.Get(ctx, currentUserID)
+	if err != nil {
+		log.Printf("UpdateNotificationPreferences DB Error loading preferences of %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to update notification preferences")
+	}
+	update := store.NotificationPreferencesUpdate{Types: map[model.NotificationType]store.NotificationChannels{}, WebhookURL: input.WebhookURL}
+	for _, in := range input.Types {
+		if !in.NotificationType.IsValid() {
+			return nil, codedError(ctx, CodeBadUserInput, fmt.Sprintf("unknown notification type %q", in.NotificationType))
+		}
+		ch, ok := update.Types[in.NotificationType]
+		if !ok {
+			if ch, ok = current.Types[in.NotificationType]; !ok {
+				ch = store.DefaultNotificationChannels
+			}
+		}
+		if in.InApp != nil {
+			ch.InApp = *in.InApp
+		}
+		if in.Email != nil {
+			ch.Email = *in.Email
+		}
+		if in.Webhook != nil {
+			ch.Webhook = *in.Webhook
+		}
+		update.Types[in.NotificationType] = ch
+	}
+
+	if err := r.NotificationPreferences.Update(ctx, currentUserID, update); err != nil {
+		log.Printf("UpdateNotificationPreferences DB Error saving preferences of %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to update notification preferences")
+	}
+	log.Printf("Notification preferences of %s updated", currentUserID)
+	return r.notificationPreferences(ctx, currentUserID)
+}
+
+// MuteNewPostNotifications is the resolver for the muteNewPostNotifications field.
+func (r *mutationResolver) MuteNewPostNotifications(ctx context.Context, authorID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	if authorID == currentUserID {
+		return false, codedError(ctx, CodeBadUserInput, "cannot mute yourself")
+	}
+	if _, err := r.Accounts.GetByID(ctx, authorID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return false, codedError(ctx, CodeNotFound, "author not found")
+		}
+		log.Printf("MuteNewPostNotifications DB Error querying author %s: %v", authorID, err)
+		return false, fmt.Errorf("internal server error")
+	}
+
+	muted, err := r.NotificationPreferences.MuteNewPosts(ctx, currentUserID, authorID)
+	if err != nil {
+		log.Printf("MuteNewPostNotifications DB Error (%s -> %s): %v", currentUserID, authorID, err)
+		return false, fmt.Errorf("failed to mute notifications")
+	}
+	return muted, nil
+}
+
+// UnmuteNewPostNotifications is the resolver for the unmuteNewPostNotifications field.
+func (r *mutationResolver) UnmuteNewPostNotifications(ctx context.Context, authorID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	unmuted, err := r.NotificationPreferences.UnmuteNewPosts(ctx, currentUserID, authorID)
+	if err != nil {
+		log.Printf("UnmuteNewPostNotifications DB Error (%s -> %s): %v", currentUserID, authorID, err)
+		return false, fmt.Errorf("failed to unmute notifications")
+	}
+	return unmuted, nil
+}
+
+// Notifications is the resolver for the notifications field.
+func (r *notificationGroupResolver) Notifications(ctx context.Context, obj *model.NotificationGroup, first *int32, after *string) (*model.NotificationConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // only reachable through @auth fields
+	if err != nil {
+		return nil, err
+	}
+	key, err := decodeGroupID(obj.GroupID)
+	if err != nil {
+		log.Printf("NotificationGroup.notifications: %v", err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	edges, err := r.Repositories.Notifications.ListForRecipient(ctx, currentUserID, r.groupFilter(key), cursor, limit) // r.Notifications is this resolver
+	if err != nil {
+		log.Printf("NotificationGroup.notifications DB Error for group %s: %v", key, err)
+		return nil, fmt.Errorf("failed to fetch notifications")
+	}
+	edges, pageInfo := trimPage(edges, limit, func(e *model.NotificationEdge) string { return e.Cursor })
+	return &model.NotificationConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
+
 // GetMyNotifications is the resolver for the getMyNotifications field.
-func (r *queryResolver) GetMyNotifications(ctx context.Context, filter *string, limit *int32, offset *int32) ([]*model.Notification, error) {
+func (r *queryResolver) GetMyNotifications(ctx context.Context, filter *model.NotificationFilter, first *int32, after *string) (*model.NotificationConnection, error) {
 	// 1. Get Current User ID
-	currentUserID, err := getCurrentUserID(ctx) // From auth.go
-	if err != nil {
-		log.Printf("GetMyNotifications Error: Not authenticated: %v", err)
-		// Return empty list if not authenticated, as they have no notifications
-		return []*model.Notification{}, nil
-		// Alternatively, return an error:
-		// return nil, fmt.Errorf("authentication required")
-	}
-
-	// 2. Database Connection
-	db, err := getDB() // From helper.go
-	if err != nil {
-		log.Printf("GetMyNotifications DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error connecting to DB")
-	}
-	defer db.Close()
-
-	// 3. Build SQL Query
-	var queryBuilder strings.Builder
-	args := []interface{}{}
-	argCounter := 1
-
-	// Base query selecting necessary fields and joining accounts for triggering user info
-	queryBuilder.WriteString(`
-		SELECT
-			n.notification_id, n.recipient_user_id, n.notification_type, n.entity_id, n.is_read, n.created_at,
-			n.triggering_user_id,
-			a.email, a.first_name, a.last_name, a.address, a.phone, a.age, a.gender, a.created_at as account_created_at, a.updated_at as account_updated_at
-		FROM notifications n
-		LEFT JOIN accounts a ON n.triggering_user_id = a.id
-		WHERE n.recipient_user_id = $`)
-	queryBuilder.WriteString(fmt.Sprintf("%d", argCounter))
-	args = append(args, currentUserID)
-	argCounter++
-
-	// --- Apply Filtering ---
-	if filter != nil {
-		log.Printf("GetMyNotifications: Applying filter '%s'", *filter)
-		if *filter == "unread" {
-			// Filter only by unread status
-			queryBuilder.WriteString(fmt.Sprintf(" AND n.is_read = false"))
-		} else if *filter == "unread_new_post" {
-			// *** ADDED: Filter by unread AND type 'new_post' ***
-			queryBuilder.WriteString(fmt.Sprintf(" AND n.is_read = false AND n.notification_type = 'new_post'"))
-		} else if *filter == "all" {
-			// No additional filter needed if fetching all (read and unread)
-		} else {
-			// Optional: Handle unknown filters, maybe default to unread or log a warning
-			log.Printf("GetMyNotifications: Unknown filter '%s' provided, defaulting to unread.", *filter)
-			queryBuilder.WriteString(fmt.Sprintf(" AND n.is_read = false"))
-		}
-	} else {
-		// Default filter if none is provided (e.g., only show unread)
-		log.Println("GetMyNotifications: No filter provided, defaulting to unread.")
-		queryBuilder.WriteString(fmt.Sprintf(" AND n.is_read = false"))
-	}
-	// --- End Filtering ---
-
-	// Ordering
-	queryBuilder.WriteString(" ORDER BY n.created_at DESC")
-
-	// --- Apply Pagination ---
-	actualLimit := int32(20) // Default limit
-	if limit != nil && *limit > 0 {
-		// Apply a reasonable maximum limit if desired
-		// if *limit > 100 { actualLimit = 100 } else { actualLimit = *limit }
-		actualLimit = *limit
-	}
-	queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d", argCounter))
-	args = append(args, actualLimit)
-	argCounter++
-
-	actualOffset := int32(0) // Default offset
-	if offset != nil && *offset >= 0 {
-		actualOffset = *offset
-	}
-	queryBuilder.WriteString(fmt.Sprintf(" OFFSET $%d", argCounter))
-	args = append(args, actualOffset)
-	argCounter++
-	// --- End Pagination ---
-
-	finalQuery := queryBuilder.String()
-	log.Printf("Executing GetMyNotifications query: [%s] with args: %v", finalQuery, args)
-
-	// 4. Execute Query
-	queryCtx, cancelQuery := context.WithTimeout(ctx, 10*time.Second)
-	defer cancelQuery()
-	rows, err := db.QueryContext(queryCtx, finalQuery, args...)
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Translate the filter
+	storeFilter, err := notificationFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Pagination
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Query
+	edges, err := r.Notifications.ListForRecipient(ctx, currentUserID, storeFilter, cursor, limit)
 	if err != nil {
 		log.Printf("GetMyNotifications DB Error executing query: %v", err)
 		return nil, fmt.Errorf("failed to fetch notifications")
 	}
-	defer rows.Close()
-
-	// 5. Scan Results
-	notifications := []*model.Notification{}
-	for rows.Next() {
-		var notif model.Notification
-		var triggeringUserID sql.NullString
-		var entityID sql.NullString
-		var createdAt time.Time
-		var accEmail, accFirstName, accLastName, accAddress, accPhone, accGender sql.NullString
-		var accAge sql.NullInt32
-		var accCreatedAt, accUpdatedAt sql.NullTime
-
-		err := rows.Scan(
-			&notif.NotificationID, &notif.RecipientUserID, &notif.NotificationType, &entityID, &notif.IsRead, &createdAt,
-			&triggeringUserID,
-			&accEmail, &accFirstName, &accLastName, &accAddress, &accPhone, &accAge, &accGender, &accCreatedAt, &accUpdatedAt,
-		)
-		if err != nil {
-			log.Printf("GetMyNotifications DB Error scanning row: %v", err)
-			continue // Skip this notification
-		}
-
-		notif.CreatedAt = createdAt.Format(time.RFC3339)
-		if entityID.Valid {
-			notif.EntityID = &entityID.String
-		} else {
-			notif.EntityID = nil
-		}
-
-		if triggeringUserID.Valid {
-			triggeringUser := &model.Account{AccountID: triggeringUserID.String}
-			if accEmail.Valid {
-				triggeringUser.Email = accEmail.String
-			}
-			if accFirstName.Valid {
-				triggeringUser.FirstName = accFirstName.String
-			}
-			if accLastName.Valid {
-				triggeringUser.LastName = accLastName.String
-			}
-			if accAddress.Valid {
-				triggeringUser.Address = &accAddress.String
-			}
-			if accPhone.Valid {
-				triggeringUser.Phone = &accPhone.String
-			}
-			if accAge.Valid {
-				triggeringUser.Age = accAge.Int32
-			}
-			if accGender.Valid {
-				triggeringUser.Gender = &accGender.String
-			}
-			if accCreatedAt.Valid {
-				triggeringUser.CreatedAt = accCreatedAt.Time.Format(time.RFC3339)
+
+	// 5. Return
+	edges, pageInfo := trimPage(edges, limit, func(e *model.NotificationEdge) string { return e.Cursor })
+	log.Printf("GetMyNotifications: Returning %d notifications for user %s with filter %+v", len(edges), currentUserID, storeFilter)
+	return &model.NotificationConnection{Edges: edges, PageInfo: pageInfo}, nil
+} // End of GetMyNotifications
+
+// GetMyNotificationGroups is the resolver for the getMyNotificationGroups field.
+func (r *queryResolver) GetMyNotificationGroups(ctx context.Context, filter *model.NotificationFilter, first *int32, after *string) (*model.NotificationGroupConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	storeFilter, err := notificationFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	limit, cursor, err := groupPageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	edges, err := r.notificationGroups(ctx, currentUserID, storeFilter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	edges, pageInfo := trimPage(edges, limit, func(e *model.NotificationGroupEdge) string { return e.Cursor })
+	return &model.NotificationGroupConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
+
+// NotificationGroup is the resolver for the notificationGroup field.
+func (r *queryResolver) NotificationGroup(ctx context.Context, groupID string) (*model.NotificationGroup, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	key, err := decodeGroupID(groupID)
+	if err != nil {
+		return nil, codedError(ctx, CodeBadUserInput, "invalid groupId")
+	}
+	// The filter matches the group's notifications only, so they form a single group.
+	edges, err := r.notificationGroups(ctx, currentUserID, r.groupFilter(key), nil, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	return edges[0].Node, nil
+}
+
+// UnreadNotificationCount is the resolver for the unreadNotificationCount field.
+func (r *queryResolver) UnreadNotificationCount(ctx context.Context) (int32, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return 0, err
+	}
+	return r.unreadCount(ctx, currentUserID)
+}
+
+// MyNotificationPreferences is the resolver for the myNotificationPreferences field.
+func (r *queryResolver) MyNotificationPreferences(ctx context.Context) (*model.NotificationPreferences, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	return r.notificationPreferences(ctx, currentUserID)
+}
+
+// NotificationAdded is the resolver for the notificationAdded field.
+func (r *subscriptionResolver) NotificationAdded(ctx context.Context) (<-chan *model.Notification, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	// ctx ends when the client stops the subscription or the socket closes, which unsubscribes.
+	ch, err := r.Notifications.Subscribe(ctx, currentUserID)
+	if err != nil {
+		log.Printf("NotificationAdded: Failed to subscribe user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to subscribe to notifications")
+	}
+	log.Printf("NotificationAdded: User %s subscribed", currentUserID)
+	return ch, nil
+}
+
+// UnreadNotificationCount is the resolver for the unreadNotificationCount field.
+func (r *subscriptionResolver) UnreadNotificationCount(ctx context.Context) (<-chan int32, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	// Subscribe before reading the current count so no change between the two is missed.
+	counts, err := r.Notifications.SubscribeUnreadCount(ctx, currentUserID)
+	if err != nil {
+		log.Printf("UnreadNotificationCount: Failed to subscribe user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to subscribe to unread count")
+	}
+	initial, err := r.unreadCount(ctx, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan int32, 1)
+	out <- initial
+	go func() {
+		defer close(out)
+		for n := range counts { // closed when ctx is done
+			select {
+			case out <- int32(n):
+			case <-ctx.Done():
+				return
 			}
-			if accUpdatedAt.Valid {
-				updatedAtStr := accUpdatedAt.Time.Format(time.RFC3339)
-				triggeringUser.UpdatedAt = &updatedAtStr
+		}
+	}()
+	return out, nil
+}
+
+// NotificationGroup returns NotificationGroupResolver implementation.
+func (r *Resolver) NotificationGroup() NotificationGroupResolver {
+	return &notificationGroupResolver{r}
+}
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+type notificationGroupResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+
+// unreadCount returns recipientID's unread notification count.
+func (r *Resolver) unreadCount(ctx context.Context, recipientID string) (int32, error) {
+	n, err := r.Notifications.CountUnread(ctx, recipientID)
+	if err != nil {
+		log.Printf("unreadCount DB Error for user %s: %v", recipientID, err)
+		return 0, fmt.Errorf("failed to count unread notifications")
+	}
+	return int32(n), nil
+}
+
+// notificationPreferences returns accountID's settings with every notification type listed.
+func (r *Resolver) notificationPreferences(ctx context.Context, accountID string) (*model.NotificationPreferences, error) {
+	prefs, err := r.NotificationPreferences.Get(ctx, accountID)
+	if err != nil {
+		log.Printf("notificationPreferences DB Error for user %s: %v", accountID, err)
+		return nil, fmt.Errorf("failed to fetch notification preferences")
+	}
+	mutedIDs, err := r.NotificationPreferences.MutedNewPostAuthors(ctx, accountID)
+	if err != nil {
+		log.Printf("notificationPreferences DB Error listing mutes of %s: %v", accountID, err)
+		return nil, fmt.Errorf("failed to fetch notification preferences")
+	}
+	muted, err := r.Accounts.GetByIDs(ctx, mutedIDs)
+	if err != nil {
+		log.Printf("notificationPreferences DB Error loading muted authors of %s: %v", accountID, err)
+		return nil, fmt.Errorf("failed to fetch notification preferences")
+	}
+
+	result := &model.NotificationPreferences{Types: []*model.NotificationTypePreference{}, MutedAuthors: []*model.Account{}}
+	for _, t := range model.AllNotificationType {
+		ch, ok := prefs.Types[t]
+		if !ok {
+			ch = store.DefaultNotificationChannels
+		}
+		result.Types = append(result.Types, &model.NotificationTypePreference{NotificationType: t, InApp: ch.InApp, Email: ch.Email, Webhook: ch.Webhook})
+	}
+	if prefs.WebhookURL != "" {
+		webhookURL := prefs.WebhookURL
+		result.WebhookURL = &webhookURL
+	}
+	for _, id := range mutedIDs {
+		if acc, ok := muted[id]; ok {
+			result.MutedAuthors = append(result.MutedAuthors, acc)
+		}
+	}
+	return result, nil
+}
+
+// notificationDeliveryQueue receives the email and webhook notifications for the delivery worker.
+const notificationDeliveryQueue = "notification_delivery"
+
+// notificationDelivery is a message on notificationDeliveryQueue.
+type notificationDelivery struct {
+	Channel          string `json:"channel"` // "email" or "webhook"
+	Address          string `json:"address"` // email address or webhook URL
+	RecipientID      string `json:"recipientId"`
+	NotificationType string `json:"notificationType"`
+	ActorID          string `json:"actorId"`
+	EntityID         string `json:"entityId"`
+	CreatedAt        string `json:"createdAt"`
+}
+
+// sendNotifications notifies recipientIDs that actorID caused a notification of type t about entityID, over
+// the channels each recipient chose. The actor is never notified. It returns the number of in-app
+// notifications created. Meant for background goroutines: failures are logged, not returned.
+func (r *Resolver) sendNotifications(ctx context.Context, t model.NotificationType, actorID, entityID string, recipientIDs []string, createdAt time.Time) int {
+	ids := make([]string, 0, len(recipientIDs))
+	for _, id := range recipientIDs {
+		if id != actorID {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0
+	}
+
+	deliveries, err := r.NotificationPreferences.Deliveries(ctx, t, actorID, ids)
+	if err != nil {
+		// Fall back to the defaults rather than dropping the notifications.
+		log.Printf("sendNotifications: Failed to load preferences for '%s' notifications, using defaults: %v", t, err)
+		deliveries = make(map[string]store.NotificationDelivery, len(ids))
+		for _, id := range ids {
+			deliveries[id] = store.NotificationDelivery{NotificationChannels: store.DefaultNotificationChannels}
+		}
+	}
+
+	created := 0
+	for _, id := range ids {
+		d, ok := deliveries[id]
+		if !ok {
+			continue // Opted out of every channel, or muted the actor
+		}
+		if d.InApp {
+			err := r.Notifications.Create(ctx, store.NewNotification{RecipientID: id, TriggeringUserID: actorID, Type: t, EntityID: entityID, CreatedAt: createdAt})
+			if err != nil {
+				log.Printf("sendNotifications: Failed to insert '%s' notification for recipient %s: %v", t, id, err)
+			} else {
+				created++
 			}
-			notif.TriggeringUser = triggeringUser
-		} else {
-			notif.TriggeringUser = nil
 		}
-		notifications = append(notifications, &notif)
+		msg := notificationDelivery{RecipientID: id, NotificationType: t.String(), ActorID: actorID, EntityID: entityID, CreatedAt: createdAt.Format(time.RFC3339)}
+		if d.NotificationChannels.Email && d.Email != "" {
+			msg.Channel, msg.Address = "email", d.Email
+			r.queueDelivery(ctx, msg)
+		}
+		if d.Webhook {
+			msg.Channel, msg.Address = "webhook", d.WebhookURL
+			r.queueDelivery(ctx, msg)
+		}
 	}
+	return created
+}
 
-	// 6. Check for errors during row iteration
-	if err = rows.Err(); err != nil {
-		log.Printf("GetMyNotifications DB Error iterating rows: %v", err)
-		return nil, fmt.Errorf("error reading notifications list")
+// queueDelivery hands an out-of-app notification to the delivery worker.
+func (r *Resolver) queueDelivery(ctx context.Context, msg notificationDelivery) {
+	sent, err := r.Queue.Publish(ctx, notificationDeliveryQueue, msg)
+	if err != nil {
+		log.Printf("sendNotifications: Failed to queue %s notification for recipient %s: %v", msg.Channel, msg.RecipientID, err)
+	} else if !sent {
+		log.Printf("sendNotifications: RabbitMQ is not configured, dropping %s notification for recipient %s", msg.Channel, msg.RecipientID)
 	}
+}
 
-	// 7. Return
-	log.Printf("GetMyNotifications: Returning %d notifications for user %s with filter '%v'", len(notifications), currentUserID, filter)
-	return notifications, nil
-} // End of GetMyNotifications
+// notificationFilter validates a NotificationFilter input and converts it for the store.
+func notificationFilter(ctx context.Context, filter *model.NotificationFilter) (store.NotificationFilter, error) {
+	var f store.NotificationFilter
+	if filter == nil {
+		return f, nil
+	}
+	f.Types = filter.Types
+	f.IsRead = filter.IsRead
+	var err error
+	if filter.CreatedAfter != nil {
+		if f.CreatedAfter, err = parseTimestamp(ctx, "createdAfter", *filter.CreatedAfter); err != nil {
+			return f, err
+		}
+	}
+	if filter.CreatedBefore != nil {
+		if f.CreatedBefore, err = parseTimestamp(ctx, "createdBefore", *filter.CreatedBefore); err != nil {
+			return f, err
+		}
+	}
+	if !f.CreatedAfter.IsZero() && !f.CreatedBefore.IsZero() && !f.CreatedAfter.Before(f.CreatedBefore) {
+		return f, codedError(ctx, CodeBadUserInput, "createdAfter must be before createdBefore")
+	}
+	return f, nil
+}
+
+// parseTimestamp parses an RFC 3339 argument, or returns a BAD_USER_INPUT error naming it.
+func parseTimestamp(ctx context.Context, name, value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, codedError(ctx, CodeBadUserInput, name+" must be an RFC 3339 timestamp")
+	}
+	return t, nil
+}
+
+// encodeGroupID returns the opaque NotificationGroup.groupId for key.
+func encodeGroupID(key store.NotificationGroupKey) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key.String()))
+}
+
+// decodeGroupID parses an ID produced by encodeGroupID.
+func decodeGroupID(id string) (store.NotificationGroupKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return store.NotificationGroupKey{}, fmt.Errorf("invalid notification group ID %q", id)
+	}
+	return store.ParseNotificationGroupKey(string(raw))
+}
+
+// notificationGroups lists recipientID's notification groups as edges, with the actor samples loaded.
+func (r *Resolver) notificationGroups(ctx context.Context, recipientID string, filter store.NotificationFilter, after *store.Cursor, limit int) ([]*model.NotificationGroupEdge, error) {
+	groups, err := r.Repositories.Notifications.ListGroups(ctx, recipientID, filter, r.groupWindow(), after, limit)
+	if err != nil {
+		log.Printf("notificationGroups DB Error for user %s: %v", recipientID, err)
+		return nil, fmt.Errorf("failed to fetch notifications")
+	}
+
+	// Load the actor samples of the whole page at once.
+	var actorIDs []string
+	for _, g := range groups {
+		actorIDs = append(actorIDs, g.ActorIDs...)
+	}
+	actors, err := r.Accounts.GetByIDs(ctx, actorIDs)
+	if err != nil {
+		log.Printf("notificationGroups DB Error loading actors: %v", err)
+		return nil, fmt.Errorf("failed to fetch notifications")
+	}
+
+	edges := make([]*model.NotificationGroupEdge, 0, len(groups))
+	for _, g := range groups {
+		node := &model.NotificationGroup{
+			GroupID:           encodeGroupID(g.Key),
+			NotificationType:  g.Key.Type,
+			Actors:            []*model.Account{},
+			ActorCount:        int32(g.ActorCount),
+			NotificationCount: int32(g.Count),
+			UnreadCount:       int32(g.UnreadCount),
+			LatestAt:          g.LatestAt.Format(time.RFC3339),
+		}
+		if g.Key.EntityID != "" {
+			entityID := g.Key.EntityID
+			node.EntityID = &entityID
+		}
+		for _, id := range g.ActorIDs {
+			if acc, ok := actors[id]; ok {
+				node.Actors = append(node.Actors, acc)
+			}
+		}
+		edges = append(edges, &model.NotificationGroupEdge{Cursor: g.Cursor().Encode(), Node: node})
+	}
+	return edges, nil
+}
+
+// groupFilter selects exactly the notifications of the group identified by key.
+func (r *Resolver) groupFilter(key store.NotificationGroupKey) store.NotificationFilter {
+	return store.NotificationFilter{
+		Types:         []model.NotificationType{key.Type},
+		EntityID:      key.EntityID,
+		CreatedAfter:  key.WindowStart,
+		CreatedBefore: key.WindowStart.Add(r.groupWindow()),
+	}
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"graphql/graph/model"
+	"graphql/store"
+
+	"github.com/99designs/gqlgen/client"
+)
+
+type testGroup struct {
+	GroupID           string
+	NotificationType  model.NotificationType
+	EntityID          *string
+	Actors            []struct{ AccountID string }
+	ActorCount        int
+	NotificationCount int
+	LatestAt          string
+}
+
+const groupFields = `groupId notificationType entityId actors { accountId } actorCount notificationCount latestAt`
+
+func TestNotificationGroupWindows(t *testing.T) {
+	c, r := newTestClient(t, func(r *Resolver) { r.Inbox.GroupWindow = time.Hour })
+	owner := register(t, c, "owner@example.com")
+	var actors []string
+	for i := 0; i < 5; i++ {
+		actors = append(actors, register(t, c, fmt.Sprintf("actor%d@example.com", i)))
+	}
+	postA, postB := createPost(t, c, owner, "a"), createPost(t, c, owner, "b")
+
+	at := func(clock string) time.Time {
+		ts, err := time.Parse(time.RFC3339, "2025-05-01T"+clock+"Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	notify := func(actor string, typ model.NotificationType, entityID, clock string) {
+		err := r.Notifications.Create(context.Background(), store.NewNotification{
+			RecipientID: owner, TriggeringUserID: actor, Type: typ, EntityID: entityID, CreatedAt: at(clock),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Windows are whole hours: 10:00-11:00 and 11:00-12:00.
+	notify(actors[0], model.NotificationTypeLike, postA, "10:05:00")
+	notify(actors[1], model.NotificationTypeLike, postA, "10:40:00")
+	notify(actors[0], model.NotificationTypeLike, postA, "10:59:59") // same actor again
+	notify(actors[2], model.NotificationTypeLike, postA, "11:00:00") // first second of the next window
+	notify(actors[3], model.NotificationTypeLike, postB, "10:30:00") // another post, same window
+	notify(actors[3], model.NotificationTypeNewFollower, actors[3], "10:10:00")
+	notify(actors[4], model.NotificationTypeNewFollower, actors[4], "10:20:00") // grouped by type alone
+
+	var resp struct {
+		GetMyNotificationGroups struct{ Edges []struct{ Node testGroup } }
+	}
+	c.MustPost(`query { getMyNotificationGroups { edges { node { `+groupFields+` } } } }`,
+		&resp, asUser(owner))
+
+	type summary struct {
+		typ      model.NotificationType
+		entityID string
+		latestAt string
+		count    int
+		actors   []string
+	}
+	var got []summary
+	for _, e := range resp.GetMyNotificationGroups.Edges {
+		g := e.Node
+		s := summary{typ: g.NotificationType, latestAt: g.LatestAt, count: g.NotificationCount}
+		if g.EntityID != nil {
+			s.entityID = *g.EntityID
+		}
+		for _, a := range g.Actors {
+			s.actors = append(s.actors, a.AccountID)
+		}
+		if g.ActorCount != len(s.actors) {
+			t.Errorf("group %s lists %d actors but actorCount is %d", g.GroupID, len(s.actors), g.ActorCount)
+		}
+		got = append(got, s)
+	}
+	want := []summary{
+		{model.NotificationTypeLike, postA, at("11:00:00").Format(time.RFC3339), 1, []string{actors[2]}},
+		{model.NotificationTypeLike, postA, at("10:59:59").Format(time.RFC3339), 3, []string{actors[0], actors[1]}},
+		{model.NotificationTypeLike, postB, at("10:30:00").Format(time.RFC3339), 1, []string{actors[3]}},
+		{model.NotificationTypeNewFollower, "", at("10:20:00").Format(time.RFC3339), 2, []string{actors[4], actors[3]}},
+	}
+	if !slices.EqualFunc(got, want, func(a, b summary) bool {
+		return a.typ == b.typ && a.entityID == b.entityID && a.latestAt == b.latestAt && a.count == b.count && slices.Equal(a.actors, b.actors)
+	}) {
+		t.Errorf("groups =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestNotificationGroupExpandsToItsWindow(t *testing.T) {
+	c, r := newTestClient(t, func(r *Resolver) { r.Inbox.GroupWindow = time.Hour })
+	owner := register(t, c, "owner@example.com")
+	actor := register(t, c, "actor@example.com")
+	post := createPost(t, c, owner, "post")
+	for _, clock := range []string{"09:15:00", "10:15:00", "10:45:00"} {
+		ts, _ := time.Parse(time.RFC3339, "2025-05-01T"+clock+"Z")
+		if err := r.Notifications.Create(context.Background(), store.NewNotification{
+			RecipientID: owner, TriggeringUserID: actor, Type: model.NotificationTypeLike, EntityID: post, CreatedAt: ts,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var list struct {
+		GetMyNotificationGroups struct {
+			Edges    []struct{ Node testGroup }
+			PageInfo struct{ EndCursor *string }
+		}
+	}
+	c.MustPost(`query { getMyNotificationGroups(first: 1) { edges { node { `+groupFields+` } } pageInfo { endCursor } } }`, &list, asUser(owner))
+	if len(list.GetMyNotificationGroups.Edges) != 1 || list.GetMyNotificationGroups.Edges[0].Node.NotificationCount != 2 {
+		t.Fatalf("first group = %+v, want the 10:00 window with 2 notifications", list.GetMyNotificationGroups.Edges)
+	}
+	groupID := list.GetMyNotificationGroups.Edges[0].Node.GroupID
+
+	var group struct {
+		NotificationGroup struct {
+			Notifications struct {
+				Edges []struct{ Node struct{ CreatedAt string } }
+			}
+		}
+	}
+	c.MustPost(`query($id: ID!) { notificationGroup(groupId: $id) { notifications { edges { node { createdAt } } } } }`,
+		&group, client.Var("id", groupID), asUser(owner))
+	var times []string
+	for _, e := range group.NotificationGroup.Notifications.Edges {
+		times = append(times, e.Node.CreatedAt)
+	}
+	if len(times) != 2 || times[0] < times[1] || times[1] < "2025-05-01T10" {
+		t.Errorf("group notifications created at %v, want the two from 10:00-11:00, newest first", times)
+	}
+
+	// The group cursor continues with the older window.
+	var next struct {
+		GetMyNotificationGroups struct{ Edges []struct{ Node testGroup } }
+	}
+	c.MustPost(`query($after: String) { getMyNotificationGroups(first: 1, after: $after) { edges { node { `+groupFields+` } } } }`,
+		&next, client.Var("after", list.GetMyNotificationGroups.PageInfo.EndCursor), asUser(owner))
+	if len(next.GetMyNotificationGroups.Edges) != 1 || next.GetMyNotificationGroups.Edges[0].Node.NotificationCount != 1 {
+		t.Errorf("group after the cursor = %+v, want the 09:00 window", next.GetMyNotificationGroups.Edges)
+	}
+}
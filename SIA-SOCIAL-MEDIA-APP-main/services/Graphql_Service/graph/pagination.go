@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+)
+
+// Page sizes for connection fields taking first/after.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageArgs validates first/after. It returns the number of rows to request from the store, which is one
+// more than the page size so the extra row reveals whether a next page exists.
+func pageArgs(ctx context.Context, first *int32, after *string) (int, *store.Cursor, error) {
+	return decodePageArgs(ctx, first, after, store.DecodeCursor)
+}
+
+// groupPageArgs is pageArgs for lists of notification groups, whose cursors hold a group key instead of a uuid.
+func groupPageArgs(ctx context.Context, first *int32, after *string) (int, *store.Cursor, error) {
+	return decodePageArgs(ctx, first, after, store.DecodeNotificationGroupCursor)
+}
+
+// decodePageArgs implements pageArgs and groupPageArgs, parsing after with decode.
+func decodePageArgs(ctx context.Context, first *int32, after *string, decode func(string) (store.Cursor, error)) (int, *store.Cursor, error) {
+	size := defaultPageSize
+	if first != nil {
+		if *first < 0 || *first > maxPageSize {
+			return 0, nil, codedError(ctx, CodeBadUserInput, "first must be between 0 and 100")
+		}
+		size = int(*first)
+	}
+	if after == nil || *after == "" {
+		return size + 1, nil, nil
+	}
+	cursor, err := decode(*after)
+	if err != nil {
+		return 0, nil, codedError(ctx, CodeBadUserInput, "invalid cursor")
+	}
+	return size + 1, &cursor, nil
+}
+
+// trimPage drops the extra row requested by pageArgs and describes the remaining edges.
+func trimPage[E any](edges []E, limit int, cursor func(E) string) ([]E, *model.PageInfo) {
+	info := &model.PageInfo{}
+	if len(edges) >= limit {
+		edges = edges[:limit-1]
+		info.HasNextPage = true
+	}
+	if len(edges) > 0 {
+		start, end := cursor(edges[0]), cursor(edges[len(edges)-1])
+		info.StartCursor, info.EndCursor = &start, &end
+	}
+	return edges, info
+}
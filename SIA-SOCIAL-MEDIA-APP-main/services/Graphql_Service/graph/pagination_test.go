@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+)
+
+// testConnection decodes a connection whose nodes alias their ID as "id".
+type testConnection struct {
+	Edges []struct {
+		Cursor string
+		Node   struct{ ID string }
+	}
+	PageInfo struct {
+		HasNextPage bool
+		EndCursor   *string
+	}
+}
+
+// fetchPage runs query, which must take $first and $after and alias its connection as "page", at the top
+// level or nested in a single-field object.
+func fetchPage(t *testing.T, c *client.Client, query string, first int, after *string, opts ...client.Option) testConnection {
+	t.Helper()
+	resp, err := c.RawPost(query, append(opts, client.Var("first", first), client.Var("after", after))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Errors != nil {
+		t.Fatalf("query failed: %s", resp.Errors)
+	}
+	data, _ := resp.Data.(map[string]any)
+	for data != nil && data["page"] == nil && len(data) == 1 {
+		for _, v := range data {
+			data, _ = v.(map[string]any)
+		}
+	}
+	raw, _ := json.Marshal(data["page"])
+	var page testConnection
+	if err := json.Unmarshal(raw, &page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+// pageThrough follows endCursor from the first page to the last, returning every node ID in order and the
+// size of each page.
+func pageThrough(t *testing.T, c *client.Client, query string, first int, opts ...client.Option) (ids []string, sizes []int) {
+	t.Helper()
+	var after *string
+	for {
+		page := fetchPage(t, c, query, first, after, opts...)
+		sizes = append(sizes, len(page.Edges))
+		for _, e := range page.Edges {
+			ids = append(ids, e.Node.ID)
+		}
+		if !page.PageInfo.HasNextPage {
+			return ids, sizes
+		}
+		if len(sizes) > 20 {
+			t.Fatal("pagination does not terminate")
+		}
+		after = page.PageInfo.EndCursor
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	c, _ := newTestClient(t)
+	author := register(t, c, "author@example.com")
+	reader := register(t, c, "reader@example.com")
+	for i := 0; i < 5; i++ {
+		follow(t, c, register(t, c, fmt.Sprintf("follower%d@example.com", i)), author)
+	}
+	follow(t, c, reader, author)
+	for i := 0; i < 5; i++ {
+		createPost(t, c, author, fmt.Sprintf("post %d", i))
+	}
+	eventually(t, "the posts to fan out", func() bool { return len(feedTitles(t, c, reader)) == 5 })
+	eventually(t, "the follower notifications", func() bool {
+		var resp struct{ UnreadNotificationCount int }
+		c.MustPost(`query { unreadNotificationCount }`, &resp, asUser(author))
+		return resp.UnreadNotificationCount == 6
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		opts  []client.Option
+		total int
+	}{
+		{"listPosts", `query($first: Int, $after: String) { page: listPosts(first: $first, after: $after) {
+			edges { cursor node { id: postId } } pageInfo { hasNextPage endCursor } } }`, nil, 5},
+		{"getFeed", `query($first: Int, $after: String) { page: getFeed(first: $first, after: $after) {
+			edges { cursor node { id: postId } } pageInfo { hasNextPage endCursor } } }`, []client.Option{asUser(reader)}, 5},
+		{"listAccounts", `query($first: Int, $after: String) { page: listAccounts(first: $first, after: $after) {
+			edges { cursor node { id: accountId } } pageInfo { hasNextPage endCursor } } }`, nil, 7},
+		{"Account.followers", `query($id: ID!, $first: Int, $after: String) { getAccount(accountId: $id) { page: followers(first: $first, after: $after) {
+			edges { cursor node { id: accountId } } pageInfo { hasNextPage endCursor } } } }`, []client.Option{client.Var("id", author)}, 6},
+		{"getMyNotifications", `query($first: Int, $after: String) { page: getMyNotifications(first: $first, after: $after) {
+			edges { cursor node { id: notificationId } } pageInfo { hasNextPage endCursor } } }`, []client.Option{asUser(author)}, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := fetchPage(t, c, tt.query, 50, nil, tt.opts...)
+			if len(all.Edges) != tt.total || all.PageInfo.HasNextPage {
+				t.Fatalf("first page of 50 has %d edges (hasNextPage %v), want all %d", len(all.Edges), all.PageInfo.HasNextPage, tt.total)
+			}
+			var want []string
+			for _, e := range all.Edges {
+				want = append(want, e.Node.ID)
+			}
+
+			ids, sizes := pageThrough(t, c, tt.query, 2, tt.opts...)
+			if !slices.Equal(ids, want) {
+				t.Errorf("paging by 2 returned %v, want %v", ids, want)
+			}
+			if sizes[len(sizes)-1] == 0 || len(sizes) != (tt.total+1)/2 {
+				t.Errorf("page sizes %v, want %d pages with the last one non-empty", sizes, (tt.total+1)/2)
+			}
+
+			// Every edge's cursor resumes right after that edge.
+			for i, e := range all.Edges[:len(all.Edges)-1] {
+				cursor := e.Cursor
+				next := fetchPage(t, c, tt.query, 1, &cursor, tt.opts...)
+				if len(next.Edges) != 1 || next.Edges[0].Node.ID != want[i+1] {
+					t.Errorf("after the cursor of edge %d got %+v, want %s", i, next.Edges, want[i+1])
+				}
+			}
+		})
+	}
+}
+
+func TestCursorSurvivesNewerRows(t *testing.T) {
+	c, _ := newTestClient(t)
+	author := register(t, c, "author@example.com")
+	for i := 0; i < 4; i++ {
+		createPost(t, c, author, fmt.Sprintf("post %d", i))
+	}
+	query := `query($first: Int, $after: String) { page: listPosts(first: $first, after: $after) {
+		edges { cursor node { id: postId } } pageInfo { hasNextPage endCursor } } }`
+	first := fetchPage(t, c, query, 2, nil)
+	createPost(t, c, author, "newer")
+	second := fetchPage(t, c, query, 2, first.PageInfo.EndCursor)
+
+	all := fetchPage(t, c, query, 50, nil)
+	var want []string
+	for _, e := range all.Edges[3:] { // all.Edges[0] is the newer post.
+		want = append(want, e.Node.ID)
+	}
+	var got []string
+	for _, e := range second.Edges {
+		got = append(got, e.Node.ID)
+	}
+	if !slices.Equal(got, want) || second.PageInfo.HasNextPage {
+		t.Errorf("second page = %v (hasNextPage %v), want %v", got, second.PageInfo.HasNextPage, want)
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	c, _ := newTestClient(t)
+	viewer := register(t, c, "viewer@example.com")
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	groupCursor := encode("2025-05-01T00:00:00Z|like|5b8f1f5e-3c52-4a53-9a36-1f1f4b0a6f0e|1746057600")
+	postCursor := encode("2025-05-01T00:00:00Z|5b8f1f5e-3c52-4a53-9a36-1f1f4b0a6f0e")
+
+	tests := []struct {
+		name  string
+		query string
+		after string
+	}{
+		{"not base64", `query($after: String) { listPosts(after: $after) { edges { cursor } } }`, "%%%"},
+		{"no separator", `query($after: String) { listPosts(after: $after) { edges { cursor } } }`, encode("2025-05-01T00:00:00Z")},
+		{"bad time", `query($after: String) { listPosts(after: $after) { edges { cursor } } }`, encode("yesterday|5b8f1f5e-3c52-4a53-9a36-1f1f4b0a6f0e")},
+		{"id not a uuid", `query($after: String) { listPosts(after: $after) { edges { cursor } } }`, encode("2025-05-01T00:00:00Z|42")},
+		{"group cursor on posts", `query($after: String) { listPosts(after: $after) { edges { cursor } } }`, groupCursor},
+		{"post cursor on groups", `query($after: String) { getMyNotificationGroups(after: $after) { edges { cursor } } }`, postCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp map[string]any
+			err := c.Post(tt.query, &resp, client.Var("after", tt.after), asUser(viewer))
+			if code := errorCode(err); code != CodeBadUserInput {
+				t.Errorf("got error %v, want code %s", err, CodeBadUserInput)
+			}
+		})
+	}
+
+	var resp map[string]any
+	if err := c.Post(`query { listPosts(first: 101) { edges { cursor } } }`, &resp); errorCode(err) != CodeBadUserInput {
+		t.Errorf("first: 101 got error %v, want code %s", err, CodeBadUserInput)
+	}
+}
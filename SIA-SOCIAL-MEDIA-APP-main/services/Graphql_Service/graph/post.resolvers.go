@@ -2,14 +2,13 @@ package graph
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"graphql/graph/model" // Ensure this path is correct
+	"graphql/store"
 	"log"
 	"strings"
 	"time"
-
-	_ "github.com/lib/pq"
 )
 
 // --- Mutation Resolvers ---
@@ -17,327 +16,215 @@ import (
 // CreatePost resolver - Belongs to mutationResolver
 // Ensure the receiver (r *mutationResolver) is correct
 func (r *mutationResolver) CreatePost(ctx context.Context, input model.CreatePostInput) (*model.Post, error) {
-	db, err := getDB()
+	authorID, err := getCurrentUserID(ctx) // @auth guarantees a user
 	if err != nil {
-		log.Printf("CreatePost DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error")
+		return nil, err
+	}
+	// Deprecated authorId: still accepted during the compatibility window, but only when it names the caller.
+	if input.AuthorID != nil {
+		log.Printf("CreatePost: deprecated input.authorId supplied by %s", authorID)
+		if *input.AuthorID != authorID {
+			log.Printf("CreatePost: rejected attempt by %s to post as %s", authorID, *input.AuthorID)
+			return nil, codedError(ctx, CodeForbidden, "authorId must be omitted or match the logged-in user")
+		}
 	}
-	defer db.Close()
 
-	var postID string
-	var createdAt time.Time
-	insertCtx, cancelInsert := context.WithTimeout(ctx, 5*time.Second)
-	defer cancelInsert()
-	query := `INSERT INTO posts (title, content, author_id, created_at) VALUES ($1, $2, $3, NOW()) RETURNING post_id, created_at`
-	err = db.QueryRowContext(insertCtx, query, input.Title, input.Content, input.AuthorID).Scan(&postID, &createdAt)
+	post, err := r.Posts.Create(ctx, store.NewPost{Title: input.Title, Content: input.Content, AuthorID: authorID})
 	if err != nil {
 		log.Printf("Error creating post: %v", err)
-		return nil, fmt.Errorf("failed to create post: %v", err)
+		return nil, fmt.Errorf("failed to create post")
 	}
-
-	log.Printf("Post created with ID: %s by author: %s", postID, input.AuthorID)
+	log.Printf("Post created with ID: %s by author: %s", post.PostID, authorID)
 
 	// --- Create Notifications for Followers ---
-	go func(authorID string, postID string, postCreatedAt time.Time) {
+	go func(authorID string, postID string) {
 		log.Printf("Starting notification fan-out for post %s by author %s", postID, authorID)
 		fanoutCtx, fanoutCancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer fanoutCancel()
-		dbFanout, errDb := getDB()
-		if errDb != nil {
-			log.Printf("CreatePost Fanout DB Error: %v", errDb)
-			return
+
+		// Timelines first, so followers see the post in their feed as soon as possible.
+		if n, errFanout := r.Timelines.FanOut(fanoutCtx, postID, r.Feed.FanoutMaxFollowers); errFanout != nil {
+			log.Printf("CreatePost Fanout: Error writing post %s to timelines: %v", postID, errFanout)
+		} else {
+			log.Printf("CreatePost Fanout: Post %s written to %d timeline(s)", postID, n)
 		}
-		defer dbFanout.Close()
 
-		followersQuery := `SELECT follower_user_id FROM follows WHERE followed_user_id = $1`
-		rows, errQuery := dbFanout.QueryContext(fanoutCtx, followersQuery, authorID)
+		followerIDs, errQuery := r.Follows.FollowerIDs(fanoutCtx, authorID)
 		if errQuery != nil {
 			log.Printf("CreatePost Fanout: Error querying followers for author %s: %v", authorID, errQuery)
 			return
 		}
-		defer rows.Close()
-
-		var followerIDs []string
-		for rows.Next() {
-			var followerID string
-			if errScan := rows.Scan(&followerID); errScan != nil {
-				log.Printf("CreatePost Fanout: Error scanning follower ID: %v", errScan)
-				continue
-			}
-			followerIDs = append(followerIDs, followerID)
-		}
-		if errRows := rows.Err(); errRows != nil {
-			log.Printf("CreatePost Fanout: Error iterating follower rows: %v", errRows)
-		}
 		if len(followerIDs) == 0 {
 			log.Printf("CreatePost Fanout: No followers found for author %s.", authorID)
 			return
 		}
 
-		log.Printf("CreatePost Fanout: Found %d followers for author %s. Inserting notifications...", len(followerIDs), authorID)
-		notifQuery := `INSERT INTO notifications (recipient_user_id, triggering_user_id, notification_type, entity_id, is_read, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
-		stmt, errPrepare := dbFanout.PrepareContext(fanoutCtx, notifQuery)
-		if errPrepare != nil {
-			log.Printf("CreatePost Fanout: Error preparing notification statement: %v", errPrepare)
-			return
+		log.Printf("CreatePost Fanout: Found %d followers for author %s. Notifying...", len(followerIDs), authorID)
+		insertedCount := r.sendNotifications(fanoutCtx, model.NotificationTypeNewPost, authorID, postID, followerIDs, time.Now())
+		log.Printf("CreatePost Fanout: Finished notifying followers. %d in-app notifications for post %s.", insertedCount, postID)
+	}(authorID, post.PostID)
+
+	return post, nil
+} // End of CreatePost function
+
+// UpdatePost is the resolver for the updatePost field.
+func (r *mutationResolver) UpdatePost(ctx context.Context, postID string, title *string, content *string) (*model.Post, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	if title == nil && content == nil {
+		return nil, codedError(ctx, CodeBadUserInput, "provide a title or content to update")
+	}
+	if title != nil && strings.TrimSpace(*title) == "" {
+		return nil, codedError(ctx, CodeBadUserInput, "title cannot be empty")
+	}
+	if content != nil && strings.TrimSpace(*content) == "" {
+		return nil, codedError(ctx, CodeBadUserInput, "content cannot be empty")
+	}
+	if err := r.checkPostAuthor(ctx, currentUserID, postID); err != nil {
+		return nil, err
+	}
+
+	post, err := r.Posts.Update(ctx, postID, currentUserID, store.PostUpdate{Title: title, Content: content})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Deleted since the author check
+			return nil, codedError(ctx, CodeNotFound, "post not found")
 		}
-		defer stmt.Close()
+		log.Printf("UpdatePost DB Error updating post %s: %v", postID, err)
+		return nil, fmt.Errorf("failed to update post")
+	}
+	log.Printf("Post %s edited by %s", postID, currentUserID)
+	return post, nil
+} // End of UpdatePost function
+
+// DeletePost is the resolver for the deletePost field.
+func (r *mutationResolver) DeletePost(ctx context.Context, postID string) (*model.Post, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkPostAuthor(ctx, currentUserID, postID); err != nil {
+		return nil, err
+	}
 
-		notificationType := "new_post"
-		isRead := false
-		triggeringUserID := authorID
-		entityID := postID
-		notificationTimestamp := postCreatedAt
-		insertedCount := 0
-		for _, recipientID := range followerIDs {
-			if recipientID == authorID {
-				continue
-			}
-			_, errInsert := stmt.ExecContext(fanoutCtx, recipientID, triggeringUserID, notificationType, entityID, isRead, notificationTimestamp)
-			if errInsert != nil {
-				log.Printf("CreatePost Fanout: Error inserting notification for recipient %s: %v", recipientID, errInsert)
-			} else {
-				insertedCount++
-			}
+	post, err := r.Posts.Delete(ctx, postID, currentUserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) { // Deleted since the author check
+			return nil, codedError(ctx, CodeNotFound, "post not found")
 		}
-		log.Printf("CreatePost Fanout: Finished inserting notifications. %d successful inserts for post %s.", insertedCount, postID)
-	}(input.AuthorID, postID, createdAt)
+		log.Printf("DeletePost DB Error deleting post %s: %v", postID, err)
+		return nil, fmt.Errorf("failed to delete post")
+	}
+	log.Printf("Post %s deleted by %s", postID, currentUserID)
+	return post, nil
+} // End of DeletePost function
 
-	// --- Publish to RabbitMQ (Optional) ---
-	go func(pID string, title string, aID string) {
-		// ... (existing rabbitmq logic, ensure it's correct if used) ...
-	}(postID, input.Title, input.AuthorID)
+// --- Field Resolvers ---
 
-	return &model.Post{PostID: postID, Title: input.Title, Content: input.Content, AuthorID: input.AuthorID, CreatedAt: createdAt.Format(time.RFC3339)}, nil
-} // End of CreatePost function
+// Author is the resolver for the author field.
+func (r *postResolver) Author(ctx context.Context, obj *model.Post) (*model.Account, error) {
+	author, err := r.dataLoaders(ctx).Accounts.Load(ctx, obj.AuthorID)
+	if err != nil {
+		log.Printf("Author DB Error loading account %s of post %s: %v", obj.AuthorID, obj.PostID, err)
+		return nil, fmt.Errorf("failed to fetch post author")
+	}
+	if author == nil {
+		log.Printf("Author: account %s of post %s does not exist", obj.AuthorID, obj.PostID)
+		return nil, fmt.Errorf("post author not found")
+	}
+	return author, nil
+} // End of Author function
+
+// Revisions is the resolver for the revisions field.
+func (r *postResolver) Revisions(ctx context.Context, obj *model.Post) ([]*model.PostRevision, error) {
+	revisions, err := r.Posts.ListRevisions(ctx, obj.PostID)
+	if err != nil {
+		log.Printf("Revisions DB Error querying post %s: %v", obj.PostID, err)
+		return nil, fmt.Errorf("failed to fetch post revisions")
+	}
+	return revisions, nil
+} // End of Revisions function
 
 // --- Query Resolvers ---
 
 // GetPost resolver - Belongs to queryResolver
 func (r *queryResolver) GetPost(ctx context.Context, postID string) (*model.Post, error) {
-	db, err := getDB()
+	viewerID, _ := getCurrentUserID(ctx) // "" for anonymous requests, who only see public authors
+	post, err := r.Posts.GetVisible(ctx, postID, viewerID)
 	if err != nil {
-		log.Printf("GetPost DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error")
-	}
-	defer db.Close()
-	var post model.Post
-	var author model.Account
-	var createdAt time.Time
-	var updatedAt sql.NullTime
-	var authorFirstName, authorLastName sql.NullString
-	var isFollowingAuthor sql.NullBool
-	currentUserID, _ := getCurrentUserID(ctx)
-	queryCtx, cancelQuery := context.WithTimeout(ctx, 5*time.Second)
-	defer cancelQuery()
-	query := `SELECT p.post_id, p.title, p.content, p.author_id, p.created_at, p.updated_at, a.first_name, a.last_name, EXISTS (SELECT 1 FROM follows WHERE follower_user_id = $1 AND followed_user_id = p.author_id) as is_following_author FROM posts p JOIN accounts a ON p.author_id = a.id WHERE p.post_id = $2`
-	err = db.QueryRowContext(queryCtx, query, currentUserID, postID).Scan(&post.PostID, &post.Title, &post.Content, &post.AuthorID, &createdAt, &updatedAt, &authorFirstName, &authorLastName, &isFollowingAuthor)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // Return nil for not found
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil // Return nil for not found, or for posts of private accounts the viewer does not follow
 		}
 		log.Printf("Error fetching post %s: %v", postID, err)
 		return nil, fmt.Errorf("failed to fetch post")
 	}
-	post.CreatedAt = createdAt.Format(time.RFC3339)
-	if updatedAt.Valid {
-		updatedStr := updatedAt.Time.Format(time.RFC3339)
-		post.UpdatedAt = &updatedStr
-	}
-	author.AccountID = post.AuthorID
-	if authorFirstName.Valid {
-		author.FirstName = authorFirstName.String
-	}
-	if authorLastName.Valid {
-		author.LastName = authorLastName.String
-	}
-	if isFollowingAuthor.Valid {
-		author.IsFollowing = &isFollowingAuthor.Bool
-	} else {
-		defaultFollowStatus := false
-		author.IsFollowing = &defaultFollowStatus
-	}
-	post.Author = &author
-	return &post, nil
+	return post, nil
 } // End of GetPost function
 
-// ListPosts resolver - Belongs to queryResolver (fetches ALL posts)
-func (r *queryResolver) ListPosts(ctx context.Context) ([]*model.Post, error) {
-	db, err := getDB()
+// ListPosts resolver - Belongs to queryResolver (pages through ALL posts)
+func (r *queryResolver) ListPosts(ctx context.Context, first *int32, after *string) (*model.PostConnection, error) {
+	limit, cursor, err := pageArgs(ctx, first, after)
 	if err != nil {
-		log.Printf("ListPosts DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error")
+		return nil, err
 	}
-	defer db.Close()
-	currentUserID, _ := getCurrentUserID(ctx)
-	query := `SELECT p.post_id, p.title, p.content, p.author_id, p.created_at, p.updated_at, a.first_name, a.last_name, EXISTS (SELECT 1 FROM follows WHERE follower_user_id = $1 AND followed_user_id = p.author_id) as is_following_author FROM posts p LEFT JOIN accounts a ON p.author_id = a.id ORDER BY p.created_at DESC LIMIT 50`
-	queryCtx, cancelQuery := context.WithTimeout(ctx, 10*time.Second)
-	defer cancelQuery()
-	rows, err := db.QueryContext(queryCtx, query, currentUserID)
+	viewerID, _ := getCurrentUserID(ctx) // "" for anonymous requests, who only see public authors
+	edges, err := r.Posts.ListRecent(ctx, viewerID, cursor, limit)
 	if err != nil {
 		log.Printf("ListPosts DB Error querying: %v", err)
 		return nil, fmt.Errorf("failed to list posts")
 	}
-	defer rows.Close()
-	posts := []*model.Post{}
-	for rows.Next() {
-		var post model.Post
-		var author model.Account
-		var createdAt time.Time
-		var updatedAt sql.NullTime
-		var authorFirstName, authorLastName sql.NullString
-		var isFollowingAuthor sql.NullBool
-		err := rows.Scan(&post.PostID, &post.Title, &post.Content, &post.AuthorID, &createdAt, &updatedAt, &authorFirstName, &authorLastName, &isFollowingAuthor)
-		if err != nil {
-			log.Printf("ListPosts DB Error scanning row: %v", err)
-			continue
-		}
-		post.CreatedAt = createdAt.Format(time.RFC3339)
-		if updatedAt.Valid {
-			formattedUpdatedAt := updatedAt.Time.Format(time.RFC3339)
-			post.UpdatedAt = &formattedUpdatedAt
-		}
-		author.AccountID = post.AuthorID
-		if authorFirstName.Valid {
-			author.FirstName = authorFirstName.String
-		}
-		if authorLastName.Valid {
-			author.LastName = authorLastName.String
-		}
-		if isFollowingAuthor.Valid {
-			author.IsFollowing = &isFollowingAuthor.Bool
-		} else {
-			defaultFollowStatus := false
-			author.IsFollowing = &defaultFollowStatus
-		}
-		post.Author = &author
-		posts = append(posts, &post)
-	}
-	if err = rows.Err(); err != nil {
-		log.Printf("ListPosts DB Error iterating rows: %v", err)
-		return nil, fmt.Errorf("error reading posts list")
-	}
-	return posts, nil
+	return postConnection(edges, limit), nil
 } // End of ListPosts function
 
 // GetFeed resolver - Belongs to queryResolver
-// Corrected signature with *int32
-func (r *queryResolver) GetFeed(ctx context.Context, limit *int32, offset *int32) ([]*model.Post, error) {
-	currentUserID, err := getCurrentUserID(ctx)
+func (r *queryResolver) GetFeed(ctx context.Context, first *int32, after *string) (*model.PostConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
 	if err != nil {
-		log.Printf("GetFeed Error: Not authenticated: %v", err)
-		return []*model.Post{}, nil
+		return nil, err
 	}
-
-	db, err := getDB()
+	limit, cursor, err := pageArgs(ctx, first, after)
 	if err != nil {
-		log.Printf("GetFeed DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error")
-	}
-	defer db.Close()
-
-	// Use int32 for defaults and parameters
-	actualLimit := int32(20)
-	if limit != nil && *limit > 0 {
-		actualLimit = *limit
-	}
-	actualOffset := int32(0)
-	if offset != nil && *offset >= 0 {
-		actualOffset = *offset
+		return nil, err
 	}
 
-	// --- Find who the current user follows ---
-	followsCtx, followsCancel := context.WithTimeout(ctx, 5*time.Second)
-	defer followsCancel()
-	followsQuery := `SELECT followed_user_id FROM follows WHERE follower_user_id = $1`
-	rowsFollows, errFollows := db.QueryContext(followsCtx, followsQuery, currentUserID)
-	if errFollows != nil {
-		log.Printf("GetFeed: Error querying follows: %v", errFollows)
-		return nil, fmt.Errorf("failed to retrieve following list")
-	}
-	defer rowsFollows.Close()
-	followedIDs := []string{}
-	for rowsFollows.Next() {
-		var followedID string
-		if errScan := rowsFollows.Scan(&followedID); errScan != nil {
-			log.Printf("GetFeed: Error scanning followed ID: %v", errScan)
-			continue
-		}
-		followedIDs = append(followedIDs, followedID)
-	}
-	if errRows := rowsFollows.Err(); errRows != nil {
-		log.Printf("GetFeed: Error iterating followed rows: %v", errRows)
-	}
-	if len(followedIDs) == 0 {
-		log.Printf("GetFeed: User %s follows no one.", currentUserID)
-		return []*model.Post{}, nil
-	}
-
-	// --- Build Query for Posts ---
-	var postsQueryBuilder strings.Builder
-	args := []interface{}{}
-	argCounter := 1
-	postsQueryBuilder.WriteString(`SELECT p.post_id, p.title, p.content, p.author_id, p.created_at, p.updated_at, a.first_name, a.last_name, EXISTS (SELECT 1 FROM follows WHERE follower_user_id = $`)
-	postsQueryBuilder.WriteString(fmt.Sprintf("%d", argCounter))
-	args = append(args, currentUserID)
-	argCounter++
-	postsQueryBuilder.WriteString(` AND followed_user_id = p.author_id) as is_following_author FROM posts p JOIN accounts a ON p.author_id = a.id WHERE p.author_id = ANY($`)
-	postsQueryBuilder.WriteString(fmt.Sprintf("%d", argCounter))
-	args = append(args, fmt.Sprintf("{%s}", strings.Join(followedIDs, ",")))
-	argCounter++
-	postsQueryBuilder.WriteString(") ORDER BY p.created_at DESC") // Added closing parenthesis for ANY
-	postsQueryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d", argCounter))
-	args = append(args, actualLimit)
-	argCounter++
-	postsQueryBuilder.WriteString(fmt.Sprintf(" OFFSET $%d", argCounter))
-	args = append(args, actualOffset)
-	argCounter++
-	finalPostsQuery := postsQueryBuilder.String()
-	log.Printf("Executing GetFeed query for user %s: [%s] with args: %v", currentUserID, finalPostsQuery, args)
-
-	// --- Execute and Scan ---
-	postsCtx, postsCancel := context.WithTimeout(ctx, 15*time.Second)
-	defer postsCancel()
-	rowsPosts, errPosts := db.QueryContext(postsCtx, finalPostsQuery, args...)
+	// --- Read the materialized timeline (plus high-follower authors, merged on read) ---
+	edges, errPosts := r.Timelines.List(ctx, currentUserID, r.Feed.FanoutMaxFollowers, cursor, limit)
 	if errPosts != nil {
 		log.Printf("GetFeed: DB Error querying posts: %v", errPosts)
 		return nil, fmt.Errorf("failed to fetch feed posts")
 	}
-	defer rowsPosts.Close()
-	posts := []*model.Post{}
-	for rowsPosts.Next() {
-		var post model.Post
-		var author model.Account
-		var createdAt time.Time
-		var updatedAt sql.NullTime
-		var authorFirstName, authorLastName sql.NullString
-		var isFollowingAuthor bool
-		errScan := rowsPosts.Scan(&post.PostID, &post.Title, &post.Content, &post.AuthorID, &createdAt, &updatedAt, &authorFirstName, &authorLastName, &isFollowingAuthor)
-		if errScan != nil {
-			log.Printf("GetFeed: Error scanning post row: %v", errScan)
-			continue
-		}
-		post.CreatedAt = createdAt.Format(time.RFC3339)
-		if updatedAt.Valid {
-			formattedUpdatedAt := updatedAt.Time.Format(time.RFC3339)
-			post.UpdatedAt = &formattedUpdatedAt
-		}
-		author.AccountID = post.AuthorID
-		if authorFirstName.Valid {
-			author.FirstName = authorFirstName.String
-		}
-		if authorLastName.Valid {
-			author.LastName = authorLastName.String
+
+	log.Printf("GetFeed: Returning %d posts for user %s", len(edges), currentUserID)
+	return postConnection(edges, limit), nil
+} // End of GetFeed function
+
+// Post returns PostResolver implementation.
+func (r *Resolver) Post() PostResolver { return &postResolver{r} }
+
+type postResolver struct{ *Resolver }
+
+// checkPostAuthor returns a NOT_FOUND or FORBIDDEN error unless postID is a live post written by userID.
+func (r *Resolver) checkPostAuthor(ctx context.Context, userID, postID string) error {
+	post, err := r.Posts.GetByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return codedError(ctx, CodeNotFound, "post not found")
 		}
-		author.IsFollowing = &isFollowingAuthor
-		post.Author = &author
-		posts = append(posts, &post)
+		log.Printf("checkPostAuthor DB Error fetching post %s: %v", postID, err)
+		return fmt.Errorf("internal server error")
 	}
-	if errRows := rowsPosts.Err(); errRows != nil {
-		log.Printf("GetFeed: Error iterating posts rows: %v", errRows)
-		return nil, fmt.Errorf("error reading feed posts list")
+	if post.AuthorID != userID {
+		return codedError(ctx, CodeForbidden, "only the author can change this post")
 	}
+	return nil
+}
 
-	log.Printf("GetFeed: Returning %d posts for user %s", len(posts), currentUserID)
-	return posts, nil
-} // End of GetFeed function
+// postConnection trims a page of posts fetched with a limit from pageArgs.
+func postConnection(edges []*model.PostEdge, limit int) *model.PostConnection {
+	if edges == nil {
+		edges = []*model.PostEdge{}
+	}
+	edges, pageInfo := trimPage(edges, limit, func(e *model.PostEdge) string { return e.Cursor })
+	return &model.PostConnection{Edges: edges, PageInfo: pageInfo}
+}
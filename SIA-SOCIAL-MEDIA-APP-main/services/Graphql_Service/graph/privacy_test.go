@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/99designs/gqlgen/client"
+)
+
+// visiblePosts reports whether viewerID ("" for anonymous) can fetch postID with getPost, and which posts
+// listPosts shows them.
+func visiblePosts(t *testing.T, c *client.Client, viewerID, postID string) (canGet bool, listed []string) {
+	t.Helper()
+	var resp struct {
+		GetPost   *struct{ PostID string }
+		ListPosts struct {
+			Edges []struct{ Node struct{ PostID string } }
+		}
+	}
+	opts := []client.Option{client.Var("id", postID)}
+	if viewerID != "" {
+		opts = append(opts, asUser(viewerID))
+	}
+	c.MustPost(`query($id: ID!) { getPost(postId: $id) { postId } listPosts(first: 50) { edges { node { postId } } } }`, &resp, opts...)
+	listed = []string{}
+	for _, e := range resp.ListPosts.Edges {
+		listed = append(listed, e.Node.PostID)
+	}
+	return resp.GetPost != nil, listed
+}
+
+func TestPrivateAccountVisibility(t *testing.T) {
+	c, _ := newTestClient(t)
+	owner := register(t, c, "owner@example.com")
+	requester := register(t, c, "requester@example.com")
+	stranger := register(t, c, "stranger@example.com")
+	var resp map[string]any
+	c.MustPost(`mutation { setAccountPrivacy(isPrivate: true) { accountId } }`, &resp, asUser(owner))
+	post := createPost(t, c, owner, "followers only")
+
+	for name, viewer := range map[string]string{"anonymous": "", "requester": requester, "stranger": stranger} {
+		if canGet, listed := visiblePosts(t, c, viewer, post); canGet || len(listed) != 0 {
+			t.Errorf("%s sees the private post: getPost %v, listPosts %v", name, canGet, listed)
+		}
+	}
+	if canGet, listed := visiblePosts(t, c, owner, post); !canGet || !slices.Equal(listed, []string{post}) {
+		t.Errorf("the owner does not see their own post: getPost %v, listPosts %v", canGet, listed)
+	}
+	err := c.Post(`mutation($id: ID!) { reactToPost(postId: $id, reaction: LIKE) { postId } }`, &resp, client.Var("id", post), asUser(stranger))
+	if errorCode(err) != CodeNotFound {
+		t.Errorf("reacting to a hidden post got %v, want %s", err, CodeNotFound)
+	}
+
+	// Following a private account only requests it.
+	var followed struct {
+		FollowUser struct {
+			IsFollowing     *bool
+			FollowRequested bool
+		}
+	}
+	c.MustPost(`mutation($id: ID!) { followUser(userIdToFollow: $id) { isFollowing followRequested } }`, &followed, client.Var("id", owner), asUser(requester))
+	if f := followed.FollowUser; f.IsFollowing == nil || *f.IsFollowing || !f.FollowRequested {
+		t.Errorf("followUser on a private account = %+v, want a pending request", f)
+	}
+	var pending struct {
+		PendingFollowRequests struct {
+			Edges []struct {
+				Node struct{ Requester struct{ AccountID string } }
+			}
+		}
+	}
+	c.MustPost(`query { pendingFollowRequests { edges { node { requester { accountId } } } } }`, &pending, asUser(owner))
+	if edges := pending.PendingFollowRequests.Edges; len(edges) != 1 || edges[0].Node.Requester.AccountID != requester {
+		t.Errorf("pendingFollowRequests = %+v, want the requester", edges)
+	}
+	if canGet, _ := visiblePosts(t, c, requester, post); canGet {
+		t.Error("a pending request reveals the private post")
+	}
+
+	var approved struct{ ApproveFollowRequest bool }
+	c.MustPost(`mutation($id: ID!) { approveFollowRequest(requesterId: $id) }`, &approved, client.Var("id", requester), asUser(owner))
+	if !approved.ApproveFollowRequest {
+		t.Fatal("approveFollowRequest returned false for a pending request")
+	}
+	if canGet, listed := visiblePosts(t, c, requester, post); !canGet || !slices.Equal(listed, []string{post}) {
+		t.Errorf("an approved follower does not see the post: getPost %v, listPosts %v", canGet, listed)
+	}
+	if got := feedTitles(t, c, requester); !slices.Equal(got, []string{"followers only"}) {
+		t.Errorf("approved follower's feed = %v, want the backfilled post", got)
+	}
+	if canGet, _ := visiblePosts(t, c, stranger, post); canGet {
+		t.Error("approving one follower reveals the post to others")
+	}
+	c.MustPost(`mutation($id: ID!) { approveFollowRequest(requesterId: $id) }`, &approved, client.Var("id", requester), asUser(owner))
+	if approved.ApproveFollowRequest {
+		t.Error("approving an existing follower returned true")
+	}
+
+	// Going public approves the stranger's pending request and shows the post to everyone.
+	c.MustPost(`mutation($id: ID!) { followUser(userIdToFollow: $id) { accountId } }`, &resp, client.Var("id", owner), asUser(stranger))
+	var public struct{ SetAccountPrivacy struct{ FollowerCount int } }
+	c.MustPost(`mutation { setAccountPrivacy(isPrivate: false) { followerCount } }`, &public, asUser(owner))
+	if public.SetAccountPrivacy.FollowerCount != 2 {
+		t.Errorf("followerCount after going public = %d, want 2", public.SetAccountPrivacy.FollowerCount)
+	}
+	if canGet, listed := visiblePosts(t, c, "", post); !canGet || !slices.Equal(listed, []string{post}) {
+		t.Errorf("anonymous viewers do not see a public account's post: getPost %v, listPosts %v", canGet, listed)
+	}
+}
@@ -6,21 +6,151 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"graphql/graph/model"
+	"graphql/store"
+	"log"
+	"strings"
+	"time"
 )
 
 // CreateProfile is the resolver for the createProfile field.
 func (r *mutationResolver) CreateProfile(ctx context.Context, input model.CreateProfileInput) (*model.Profile, error) {
-	panic(fmt.Errorf("not implemented: CreateProfile - createProfile"))
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	username := strings.TrimSpace(input.Username)
+	if username == "" {
+		return nil, codedError(ctx, CodeBadUserInput, "username must not be empty")
+	}
+	if err := validateDateOfBirth(ctx, input.DateOfBirth); err != nil {
+		return nil, err
+	}
+	if input.DateOfBirth != nil && *input.DateOfBirth == "" {
+		input.DateOfBirth = nil
+	}
+	account, err := r.Accounts.GetByID(ctx, currentUserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "account not found")
+		}
+		log.Printf("CreateProfile DB Error querying account %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	email := ""
+	if account.Email != nil {
+		email = *account.Email
+	}
+
+	profile, err := r.Profiles.Create(ctx, store.NewProfile{
+		ProfileID:         currentUserID,
+		Username:          username,
+		Email:             email,
+		FirstName:         input.FirstName,
+		MiddleName:        input.MiddleName,
+		LastName:          input.LastName,
+		Bio:               input.Bio,
+		ProfilePictureURL: input.ProfilePictureURL,
+		BannerPictureURL:  input.BannerPictureURL,
+		DateOfBirth:       input.DateOfBirth,
+		Address:           input.Address,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrUsernameTaken) {
+			return nil, codedError(ctx, CodeUsernameTaken, fmt.Sprintf("username %q is already taken", username))
+		}
+		if errors.Is(err, store.ErrConflict) {
+			return nil, codedError(ctx, CodeConflict, "a profile already exists for this account or email")
+		}
+		log.Printf("CreateProfile DB Error for %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to create profile")
+	}
+	return profile, nil
+}
+
+// UpdateMyProfile is the resolver for the updateMyProfile field.
+func (r *mutationResolver) UpdateMyProfile(ctx context.Context, input model.UpdateProfileInput) (*model.Profile, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	if input.Username != nil {
+		trimmed := strings.TrimSpace(*input.Username)
+		if trimmed == "" {
+			return nil, codedError(ctx, CodeBadUserInput, "username must not be empty")
+		}
+		input.Username = &trimmed
+	}
+	if err := validateDateOfBirth(ctx, input.DateOfBirth); err != nil {
+		return nil, err
+	}
+
+	profile, err := r.Profiles.Update(ctx, currentUserID, store.ProfileUpdate{
+		Username:          input.Username,
+		MiddleName:        input.MiddleName,
+		Bio:               input.Bio,
+		ProfilePictureURL: input.ProfilePictureURL,
+		BannerPictureURL:  input.BannerPictureURL,
+		DateOfBirth:       input.DateOfBirth,
+		Address:           input.Address,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "no profile exists for this account yet; call createProfile first")
+		}
+		if errors.Is(err, store.ErrUsernameTaken) {
+			return nil, codedError(ctx, CodeUsernameTaken, fmt.Sprintf("username %q is already taken", *input.Username))
+		}
+		log.Printf("UpdateMyProfile DB Error for %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to update profile")
+	}
+	return profile, nil
 }
 
 // GetProfile is the resolver for the getProfile field.
 func (r *queryResolver) GetProfile(ctx context.Context, profileID string) (*model.Profile, error) {
-	panic(fmt.Errorf("not implemented: GetProfile - getProfile"))
+	profile, err := r.Profiles.GetByID(ctx, profileID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "profile not found")
+		}
+		log.Printf("GetProfile DB Error querying profile %s: %v", profileID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	// A profile's ID is its account's, so like GetAccount it is hidden from accounts its owner blocked.
+	if viewerID, _ := getCurrentUserID(ctx); viewerID != "" {
+		blocked, err := r.Blocks.IsBlocking(ctx, profileID, viewerID)
+		if err != nil {
+			log.Printf("GetProfile DB Error checking blocks (%s -> %s): %v", profileID, viewerID, err)
+			return nil, fmt.Errorf("internal server error")
+		}
+		if blocked {
+			return nil, codedError(ctx, CodeNotFound, "profile not found")
+		}
+	}
+	return profile, nil
 }
 
 // ListProfiles is the resolver for the listProfiles field.
 func (r *queryResolver) ListProfiles(ctx context.Context) ([]*model.Profile, error) {
-	panic(fmt.Errorf("not implemented: ListProfiles - listProfiles"))
+	viewerID, _ := getCurrentUserID(ctx) // "" for anonymous requests, who see every profile
+	profiles, err := r.Profiles.List(ctx, viewerID)
+	if err != nil {
+		log.Printf("ListProfiles DB Error querying: %v", err)
+		return nil, fmt.Errorf("failed to list profiles")
+	}
+	return profiles, nil
+}
+
+// validateDateOfBirth rejects anything that is not an empty string or a YYYY-MM-DD date.
+func validateDateOfBirth(ctx context.Context, dateOfBirth *string) error {
+	if dateOfBirth == nil || *dateOfBirth == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", *dateOfBirth); err != nil {
+		return codedError(ctx, CodeBadUserInput, "dateOfBirth must be formatted as YYYY-MM-DD")
+	}
+	return nil
 }
@@ -0,0 +1,167 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.72
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/loaders"
+	"graphql/store"
+	"log"
+	"strings"
+	"time"
+)
+
+// ReactionSummary is the resolver for the reactionSummary field.
+func (r *commentResolver) ReactionSummary(ctx context.Context, obj *model.Comment) (*model.ReactionSummary, error) {
+	return r.reactionSummary(ctx, store.ReactionTarget{Kind: store.ReactionTargetComment, ID: obj.CommentID})
+}
+
+// MyReaction is the resolver for the myReaction field.
+func (r *commentResolver) MyReaction(ctx context.Context, obj *model.Comment) (*model.ReactionType, error) {
+	return r.myReaction(ctx, store.ReactionTarget{Kind: store.ReactionTargetComment, ID: obj.CommentID})
+}
+
+// ReactToPost is the resolver for the reactToPost field.
+func (r *mutationResolver) ReactToPost(ctx context.Context, postID string, reaction model.ReactionType) (*model.Post, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.Posts.GetVisible(ctx, postID, currentUserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, codedError(ctx, CodeNotFound, "post not found")
+		}
+		log.Printf("ReactToPost DB Error fetching post %s: %v", postID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+
+	target := store.ReactionTarget{Kind: store.ReactionTargetPost, ID: postID}
+	if err := r.react(ctx, target, currentUserID, reaction, post.AuthorID, postID); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// ReactToComment is the resolver for the reactToComment field.
+func (r *mutationResolver) ReactToComment(ctx context.Context, commentID string, reaction model.ReactionType) (*model.Comment, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := r.liveComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Posts.GetVisible(ctx, comment.PostID, currentUserID); err != nil {
+		if errors.Is(err, store.ErrNotFound) { // The post is gone or its author is private
+			return nil, codedError(ctx, CodeNotFound, "comment not found")
+		}
+		log.Printf("ReactToComment DB Error fetching post %s: %v", comment.PostID, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+
+	target := store.ReactionTarget{Kind: store.ReactionTargetComment, ID: commentID}
+	if err := r.react(ctx, target, currentUserID, reaction, comment.AuthorID, comment.PostID); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// RemoveReaction is the resolver for the removeReaction field.
+func (r *mutationResolver) RemoveReaction(ctx context.Context, postID *string, commentID *string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+
+	var target store.ReactionTarget
+	switch {
+	case postID != nil && commentID == nil:
+		target = store.ReactionTarget{Kind: store.ReactionTargetPost, ID: *postID}
+	case commentID != nil && postID == nil:
+		target = store.ReactionTarget{Kind: store.ReactionTargetComment, ID: *commentID}
+	default:
+		return false, codedError(ctx, CodeBadUserInput, "exactly one of postId or commentId is required")
+	}
+
+	removed, err := r.Reactions.Remove(ctx, target, currentUserID)
+	if err != nil {
+		log.Printf("RemoveReaction DB Error removing reaction of %s from %s %s: %v", currentUserID, target.Kind, target.ID, err)
+		return false, fmt.Errorf("failed to remove reaction")
+	}
+	return removed, nil
+}
+
+// ReactionSummary is the resolver for the reactionSummary field.
+func (r *postResolver) ReactionSummary(ctx context.Context, obj *model.Post) (*model.ReactionSummary, error) {
+	return r.reactionSummary(ctx, store.ReactionTarget{Kind: store.ReactionTargetPost, ID: obj.PostID})
+}
+
+// MyReaction is the resolver for the myReaction field.
+func (r *postResolver) MyReaction(ctx context.Context, obj *model.Post) (*model.ReactionType, error) {
+	return r.myReaction(ctx, store.ReactionTarget{Kind: store.ReactionTargetPost, ID: obj.PostID})
+}
+
+// react stores the reaction and, on an account's first reaction to the target, notifies the target's author.
+// postID is the notification entity, so a client can open the post a reaction belongs to.
+func (r *Resolver) react(ctx context.Context, target store.ReactionTarget, accountID string, reaction model.ReactionType, authorID, postID string) error {
+	created, err := r.Reactions.Set(ctx, target, accountID, strings.ToLower(reaction.String()))
+	if err != nil {
+		log.Printf("react DB Error setting reaction of %s on %s %s: %v", accountID, target.Kind, target.ID, err)
+		return fmt.Errorf("failed to save reaction")
+	}
+	if !created || authorID == accountID {
+		return nil // Switching reactions or reacting to your own content notifies nobody
+	}
+
+	go func(reactorID string, authorID string, postID string) {
+		notifCtx, notifCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer notifCancel()
+		r.sendNotifications(notifCtx, model.NotificationTypeLike, reactorID, postID, []string{authorID}, time.Now())
+	}(accountID, authorID, postID)
+	return nil
+}
+
+func (r *Resolver) reactionSummary(ctx context.Context, target store.ReactionTarget) (*model.ReactionSummary, error) {
+	counts, err := r.dataLoaders(ctx).ReactionCounts.Load(ctx, target)
+	if err != nil {
+		log.Printf("reactionSummary DB Error counting reactions on %s %s: %v", target.Kind, target.ID, err)
+		return nil, fmt.Errorf("failed to fetch reactions")
+	}
+	summary := &model.ReactionSummary{Counts: []*model.ReactionCount{}}
+	for _, reaction := range model.AllReactionType {
+		n := counts[strings.ToLower(reaction.String())]
+		if n == 0 {
+			continue
+		}
+		summary.Counts = append(summary.Counts, &model.ReactionCount{Reaction: reaction, Count: int32(n)})
+		summary.Total += int32(n)
+	}
+	return summary, nil
+}
+
+// myReaction returns the logged-in account's reaction to target, or nil for anonymous requests.
+func (r *Resolver) myReaction(ctx context.Context, target store.ReactionTarget) (*model.ReactionType, error) {
+	currentUserID, err := getCurrentUserID(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	reaction, err := r.dataLoaders(ctx).Reactions.Load(ctx, loaders.ReactionKey{Target: target, AccountID: currentUserID})
+	if err != nil {
+		log.Printf("myReaction DB Error fetching reaction of %s on %s %s: %v", currentUserID, target.Kind, target.ID, err)
+		return nil, fmt.Errorf("failed to fetch reaction")
+	}
+	if reaction == "" {
+		return nil, nil
+	}
+	rt := model.ReactionType(strings.ToUpper(reaction))
+	return &rt, nil
+}
@@ -1,7 +1,57 @@
 package graph
 
+import (
+	"context"
+	"graphql/auth"
+	"graphql/cache"
+	"graphql/config"
+	"graphql/loaders"
+	"graphql/mq"
+	"graphql/store"
+	"time"
+)
+
 // This file will not be regenerated automatically.
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
-type Resolver struct{}
+// Resolver holds the repositories every resolver reads and writes through.
+// server.go wires store/postgres; tests can wire store/memory instead.
+type Resolver struct {
+	store.Repositories
+	Passwords *auth.PasswordHasher
+	Tokens    *auth.TokenIssuer // nil when AUTH_TOKEN_SECRET is unset; login is then unavailable
+	Feed      config.FeedConfig
+	// Inbox holds the notification settings; the name avoids shadowing the Notifications repository.
+	Inbox config.NotificationConfig
+	Queue *mq.Publisher // nil when RABBITMQ_URL is unset; publishing is then skipped
+	// SuggestionCache holds each viewer's ranked suggestions. Resolvers changing whom a viewer follows, blocks
+	// or mutes drop the viewer's entry. nil disables caching.
+	SuggestionCache *cache.TTL[string, []store.Suggestion]
+}
+
+// suggestedAccounts page sizes. Suggest always ranks maxSuggestions accounts, which are then cached.
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 50
+)
+
+// defaultGroupWindow is used when Inbox.GroupWindow is unset, as in resolver tests.
+const defaultGroupWindow = 24 * time.Hour
+
+// groupWindow returns the width of the windows notifications are grouped in.
+func (r *Resolver) groupWindow() time.Duration {
+	if r.Inbox.GroupWindow > 0 {
+		return r.Inbox.GroupWindow
+	}
+	return defaultGroupWindow
+}
+
+// dataLoaders returns the operation's batch loaders. Operations run without loaders.Extension,
+// such as resolver tests, get a fresh set per call: still correct, but without batching.
+func (r *Resolver) dataLoaders(ctx context.Context) *loaders.Loaders {
+	if l := loaders.For(ctx); l != nil {
+		return l
+	}
+	return loaders.New(ctx, r.Repositories)
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"graphql/auth"
+	"graphql/store/memory"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+)
+
+// newTestClient serves the schema over a Resolver backed by store/memory. configure, if given, adjusts the
+// Resolver before the server is built; the Resolver is returned so tests can reach the repositories.
+func newTestClient(t *testing.T, configure ...func(*Resolver)) (*client.Client, *Resolver) {
+	t.Helper()
+	r := &Resolver{Repositories: memory.New(), Passwords: auth.NewPasswordHasher(4)}
+	for _, f := range configure {
+		f(r)
+	}
+	srv := handler.New(NewExecutableSchema(NewConfig(r)))
+	srv.AddTransport(transport.POST{})
+	return client.New(srv), r
+}
+
+// asUser authenticates a request as accountID, as AuthMiddleware does for a valid token.
+func asUser(accountID string) client.Option {
+	return func(req *client.Request) {
+		req.HTTP = req.HTTP.WithContext(context.WithValue(req.HTTP.Context(), AuthUserIDKey, accountID))
+	}
+}
+
+// errorCode returns the extensions.code of the first GraphQL error in err, or "".
+func errorCode(err error) string {
+	var raw client.RawJsonError
+	if !errors.As(err, &raw) {
+		return ""
+	}
+	var errs []struct {
+		Extensions struct{ Code string }
+	}
+	if json.Unmarshal(raw.RawMessage, &errs) != nil || len(errs) == 0 {
+		return ""
+	}
+	return errs[0].Extensions.Code
+}
+
+// eventually fails the test unless cond holds within a second. Fan-out and notifications run in goroutines
+// after the mutation returns.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func register(t *testing.T, c *client.Client, email string) string {
+	t.Helper()
+	var resp struct{ Register struct{ AccountID string } }
+	c.MustPost(`mutation($email: String!) {
+		register(input: {email: $email, password: "password", firstName: "First", lastName: "Last", age: 30}) { accountId }
+	}`, &resp, client.Var("email", email))
+	return resp.Register.AccountID
+}
+
+func createPost(t *testing.T, c *client.Client, authorID, title string) string {
+	t.Helper()
+	var resp struct{ CreatePost struct{ PostID string } }
+	c.MustPost(`mutation($title: String!) { createPost(input: {title: $title, content: "content"}) { postId } }`,
+		&resp, client.Var("title", title), asUser(authorID))
+	return resp.CreatePost.PostID
+}
+
+func follow(t *testing.T, c *client.Client, followerID, followedID string) {
+	t.Helper()
+	var resp map[string]any
+	c.MustPost(`mutation($id: ID!) { followUser(userIdToFollow: $id) { accountId } }`, &resp, client.Var("id", followedID), asUser(followerID))
+}
+
+// feedTitles returns the titles on accountID's first page of getFeed, newest first.
+func feedTitles(t *testing.T, c *client.Client, accountID string) []string {
+	t.Helper()
+	var resp struct {
+		GetFeed struct {
+			Edges []struct{ Node struct{ Title string } }
+		}
+	}
+	c.MustPost(`query { getFeed(first: 50) { edges { node { title } } } }`, &resp, asUser(accountID))
+	titles := []string{}
+	for _, e := range resp.GetFeed.Edges {
+		titles = append(titles, e.Node.Title)
+	}
+	return titles
+}
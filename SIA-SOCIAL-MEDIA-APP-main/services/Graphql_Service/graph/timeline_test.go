@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"graphql/config"
+	"graphql/store"
+
+	"github.com/99designs/gqlgen/client"
+)
+
+// storedTimeline returns the titles written to ownerID's timeline, leaving out posts merged in at read time.
+func storedTimeline(t *testing.T, r *Resolver, ownerID string) []string {
+	t.Helper()
+	edges, err := r.Timelines.List(context.Background(), ownerID, math.MaxInt, nil, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := []string{}
+	for _, e := range edges {
+		titles = append(titles, e.Node.Title)
+	}
+	return titles
+}
+
+func TestTimelineFanOutAndMergeOnRead(t *testing.T) {
+	// Authors with more than one follower are merged in at read time.
+	c, r := newTestClient(t, func(r *Resolver) { r.Feed = config.FeedConfig{FanoutMaxFollowers: 1, BackfillPosts: 2} })
+	reader := register(t, c, "reader@example.com")
+	other := register(t, c, "other@example.com")
+	author := register(t, c, "author@example.com")
+	celebrity := register(t, c, "celebrity@example.com")
+
+	// Written through the repository so no fan-out races the backfill on follow.
+	for _, title := range []string{"author 1", "author 2", "author 3"} {
+		if _, err := r.Posts.Create(context.Background(), store.NewPost{Title: title, Content: "content", AuthorID: author}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	follow(t, c, reader, author)
+	if got, want := storedTimeline(t, r, reader), []string{"author 3", "author 2"}; !slices.Equal(got, want) {
+		t.Errorf("after following, the timeline holds %v, want the %d most recent posts %v", got, len(want), want)
+	}
+
+	follow(t, c, reader, celebrity)
+	follow(t, c, other, celebrity)
+	celebrityPost := createPost(t, c, celebrity, "celebrity 1")
+	createPost(t, c, author, "author 4")
+	eventually(t, "author 4 to fan out", func() bool { return slices.Contains(storedTimeline(t, r, reader), "author 4") })
+
+	if n, err := r.Timelines.FanOut(context.Background(), celebrityPost, r.Feed.FanoutMaxFollowers); err != nil || n != 0 {
+		t.Errorf("fanning out the celebrity's post wrote %d timelines (err %v), want none", n, err)
+	}
+	if got := storedTimeline(t, r, reader); slices.Contains(got, "celebrity 1") {
+		t.Errorf("the celebrity's post was fanned out: timeline holds %v", got)
+	}
+	if got, want := feedTitles(t, c, reader), []string{"author 4", "celebrity 1", "author 3", "author 2"}; !slices.Equal(got, want) {
+		t.Errorf("reader's feed = %v, want %v", got, want)
+	}
+	if got, want := feedTitles(t, c, other), []string{"celebrity 1"}; !slices.Equal(got, want) {
+		t.Errorf("other's feed = %v, want %v", got, want)
+	}
+
+	var resp map[string]any
+	c.MustPost(`mutation($id: ID!) { unfollowUser(userIdToUnfollow: $id) { accountId } }`, &resp, client.Var("id", celebrity), asUser(reader))
+	if got, want := feedTitles(t, c, reader), []string{"author 4", "author 3", "author 2"}; !slices.Equal(got, want) {
+		t.Errorf("after unfollowing the celebrity, feed = %v, want %v", got, want)
+	}
+	c.MustPost(`mutation($id: ID!) { unfollowUser(userIdToUnfollow: $id) { accountId } }`, &resp, client.Var("id", author), asUser(reader))
+	if got := storedTimeline(t, r, reader); len(got) != 0 {
+		t.Errorf("after unfollowing the author, the timeline still holds %v", got)
+	}
+}
+
+func TestTimelineDropsDeletedPosts(t *testing.T) {
+	c, r := newTestClient(t, func(r *Resolver) { r.Feed = config.FeedConfig{FanoutMaxFollowers: 10, BackfillPosts: 10} })
+	reader := register(t, c, "reader@example.com")
+	author := register(t, c, "author@example.com")
+	follow(t, c, reader, author)
+	createPost(t, c, author, "kept")
+	deleted := createPost(t, c, author, "deleted")
+	eventually(t, "both posts to fan out", func() bool { return len(storedTimeline(t, r, reader)) == 2 })
+
+	var resp map[string]any
+	c.MustPost(`mutation($id: ID!) { deletePost(postId: $id) { postId } }`, &resp, client.Var("id", deleted), asUser(author))
+	if got, want := feedTitles(t, c, reader), []string{"kept"}; !slices.Equal(got, want) {
+		t.Errorf("feed = %v, want %v", got, want)
+	}
+}
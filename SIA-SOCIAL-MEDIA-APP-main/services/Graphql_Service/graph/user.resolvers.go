@@ -6,28 +6,16 @@ package graph
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
+	"graphql/auth"
 	"graphql/graph/model" // Adjust import path if needed
+	"graphql/loaders"
+	"graphql/store"
 	"log"
-	"os"
 	"time"
-
-	_ "github.com/lib/pq" // PostgreSQL driver
-	amqp091 "github.com/rabbitmq/amqp091-go"
 )
 
-// Add this AccountResolver interface definition
-type AccountResolver interface {
-	IsFollowing(ctx context.Context, obj *model.Account) (*bool, error)
-}
-
-// Ensure this line exists to connect the interface to the main Resolver struct
-func (r *Resolver) Account() AccountResolver { return &accountResolver{r} }
-
-// Add this struct definition
-type accountResolver struct{ *Resolver }
-
 // IsFollowing is the resolver for the isFollowing field.
 func (r *accountResolver) IsFollowing(ctx context.Context, obj *model.Account) (*bool, error) {
 	currentUserID, err := getCurrentUserID(ctx)
@@ -40,18 +28,7 @@ func (r *accountResolver) IsFollowing(ctx context.Context, obj *model.Account) (
 		f := false
 		return &f, nil
 	}
-	db, err := getDB()
-	if err != nil {
-		log.Printf("IsFollowing resolver DB Error: %v", err)
-		f := false
-		return &f, nil
-	}
-	defer db.Close()
-	var exists bool
-	queryCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
-	query := `SELECT EXISTS (SELECT 1 FROM follows WHERE follower_user_id = $1 AND followed_user_id = $2)`
-	err = db.QueryRowContext(queryCtx, query, currentUserID, targetUserID).Scan(&exists)
+	exists, err := r.dataLoaders(ctx).Follows.Load(ctx, loaders.FollowKey{FollowerID: currentUserID, FollowedID: targetUserID})
 	if err != nil {
 		log.Printf("IsFollowing resolver DB query error (%s -> %s): %v", currentUserID, targetUserID, err)
 		f := false
@@ -60,277 +37,467 @@ func (r *accountResolver) IsFollowing(ctx context.Context, obj *model.Account) (
 	return &exists, nil
 }
 
+// FollowRequested is the resolver for the followRequested field.
+func (r *accountResolver) FollowRequested(ctx context.Context, obj *model.Account) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx)
+	if err != nil || currentUserID == obj.AccountID {
+		return false, nil
+	}
+	requested, err := r.dataLoaders(ctx).FollowRequests.Load(ctx, loaders.FollowKey{FollowerID: currentUserID, FollowedID: obj.AccountID})
+	if err != nil {
+		log.Printf("FollowRequested DB Error (%s -> %s): %v", currentUserID, obj.AccountID, err)
+		return false, fmt.Errorf("failed to fetch follow request state")
+	}
+	return requested, nil
+}
+
+// FollowsYou is the resolver for the followsYou field.
+func (r *accountResolver) FollowsYou(ctx context.Context, obj *model.Account) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx)
+	if err != nil || currentUserID == obj.AccountID {
+		return false, nil
+	}
+	follows, err := r.dataLoaders(ctx).FollowedBy.Load(ctx, loaders.FollowKey{FollowerID: obj.AccountID, FollowedID: currentUserID})
+	if err != nil {
+		log.Printf("FollowsYou DB Error (%s -> %s): %v", obj.AccountID, currentUserID, err)
+		return false, fmt.Errorf("failed to fetch follow state")
+	}
+	return follows, nil
+}
+
+// Followers is the resolver for the followers field.
+func (r *accountResolver) Followers(ctx context.Context, obj *model.Account, first *int32, after *string) (*model.AccountConnection, error) {
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.Follows.Followers(ctx, obj.AccountID, cursor, limit)
+	if err != nil {
+		log.Printf("Followers DB Error querying followers of %s: %v", obj.AccountID, err)
+		return nil, fmt.Errorf("failed to fetch followers")
+	}
+	return accountConnection(edges, limit), nil
+}
+
+// Following is the resolver for the following field.
+func (r *accountResolver) Following(ctx context.Context, obj *model.Account, first *int32, after *string) (*model.AccountConnection, error) {
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.Follows.Following(ctx, obj.AccountID, cursor, limit)
+	if err != nil {
+		log.Printf("Following DB Error querying accounts followed by %s: %v", obj.AccountID, err)
+		return nil, fmt.Errorf("failed to fetch followed accounts")
+	}
+	return accountConnection(edges, limit), nil
+}
+
+// FollowerCount is the resolver for the followerCount field.
+func (r *accountResolver) FollowerCount(ctx context.Context, obj *model.Account) (int32, error) {
+	counts, err := r.followCounts(ctx, obj.AccountID)
+	return int32(counts.Followers), err
+}
+
+// FollowingCount is the resolver for the followingCount field.
+func (r *accountResolver) FollowingCount(ctx context.Context, obj *model.Account) (int32, error) {
+	counts, err := r.followCounts(ctx, obj.AccountID)
+	return int32(counts.Following), err
+}
+
 // --- Mutation Resolvers ---
 
 // Register is the resolver for the register field.
 func (r *mutationResolver) Register(ctx context.Context, input model.RegisterInput) (*model.Account, error) {
-	db, err := getDB()
+	passwordHash, err := r.Passwords.Hash(input.Password)
 	if err != nil {
-		log.Printf("Register DB Error: %v", err)
-		return nil, fmt.Errorf("internal error connecting to DB")
+		if errors.Is(err, auth.ErrPasswordTooLong) {
+			return nil, codedError(ctx, CodeBadUserInput, err.Error())
+		}
+		log.Printf("Register Error hashing password: %v", err)
+		return nil, fmt.Errorf("internal error registering account")
 	}
-	defer db.Close()
-
-	var accountID string
-	var createdAt time.Time
-	insertCtx, cancelInsert := context.WithTimeout(ctx, 5*time.Second)
-	defer cancelInsert()
-	err = db.QueryRowContext(insertCtx, `
-		INSERT INTO accounts (email, password, first_name, last_name, address, phone, age, gender, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
-		RETURNING id, created_at
-	`, input.Email, input.Password, input.FirstName, input.LastName, input.Address, input.Phone, input.Age, input.Gender).Scan(&accountID, &createdAt)
 
+	account, err := r.Accounts.Create(ctx, store.NewAccount{
+		Email:        input.Email,
+		PasswordHash: passwordHash,
+		FirstName:    input.FirstName,
+		LastName:     input.LastName,
+		Address:      input.Address,
+		Phone:        input.Phone,
+		Age:          input.Age,
+		Gender:       input.Gender,
+	})
 	if err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil, fmt.Errorf("an account with this email already exists")
+		}
 		log.Printf("Register DB Error inserting account: %v", err)
 		return nil, fmt.Errorf("internal error registering account")
 	}
+	accountID := account.AccountID
 
 	// Publish a message to RabbitMQ
-	go func() {
-		rabbitmqURL := os.Getenv("RABBITMQ_URL")
-		if rabbitmqURL == "" {
-			log.Println("Register: RABBITMQ_URL not set, skipping message publish.")
-			return
-		}
-		conn, err := amqp091.Dial(rabbitmqURL)
-		if err != nil {
-			log.Printf("Register: failed to connect to RabbitMQ: %v", err)
-			return
-		}
-		defer conn.Close()
-		ch, err := conn.Channel()
-		if err != nil {
-			log.Printf("Register: failed to open a channel: %v", err)
-			return
-		}
-		defer ch.Close()
-		q, err := ch.QueueDeclare("user_registered", true, false, false, false, nil)
-		if err != nil {
-			log.Printf("Register: failed to declare a queue: %v", err)
-			return
-		}
-		body := fmt.Sprintf(`{"accountId":"%s","email":"%s"}`, accountID, input.Email)
+	go func(accountID, email string) {
 		pubCtx, pubCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer pubCancel()
-		err = ch.PublishWithContext(pubCtx, "", q.Name, false, false, amqp091.Publishing{ContentType: "application/json", Body: []byte(body), Timestamp: time.Now()})
+		published, err := r.Queue.Publish(pubCtx, "user_registered", map[string]string{"accountId": accountID, "email": email})
 		if err != nil {
 			log.Printf("Register: failed to publish user_registered message: %v", err)
+		} else if !published {
+			log.Println("Register: RABBITMQ_URL not set, skipping message publish.")
 		} else {
 			log.Printf("Register: Published user_registered message for %s", accountID)
 		}
-	}()
+	}(accountID, input.Email)
 
-	return &model.Account{AccountID: accountID, Email: input.Email, FirstName: input.FirstName, LastName: input.LastName, Address: input.Address, Phone: input.Phone, Age: input.Age, Gender: input.Gender, CreatedAt: createdAt.Format(time.RFC3339)}, nil
+	return account, nil
 }
 
 // FollowUser is the resolver for the followUser field.
-func (r *mutationResolver) FollowUser(ctx context.Context, userIdToFollow string) (*model.Account, error) {
-	currentUserID, err := getCurrentUserID(ctx)
+func (r *mutationResolver) FollowUser(ctx context.Context, userIDToFollow string) (*model.Account, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
 	if err != nil {
-		log.Printf("FollowUser Error: Not authenticated: %v", err)
-		return nil, fmt.Errorf("authentication required")
+		return nil, err
 	}
-	if currentUserID == userIdToFollow {
+	if currentUserID == userIDToFollow {
 		return nil, fmt.Errorf("cannot follow yourself")
 	}
 
-	db, err := getDB()
+	followedAccount, err := r.Accounts.GetByID(ctx, userIDToFollow)
 	if err != nil {
-		log.Printf("FollowUser DB Error: %v", err)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("user to follow not found")
+		}
+		log.Printf("FollowUser DB Error querying followed user %s: %v", userIDToFollow, err)
 		return nil, fmt.Errorf("internal server error")
 	}
-	defer db.Close()
 
-	var followedAccount model.Account
-	var createdAt time.Time
-	var updatedAt sql.NullTime
-	queryCtx, cancelQuery := context.WithTimeout(ctx, 5*time.Second)
-	err = db.QueryRowContext(queryCtx, `SELECT id, email, first_name, last_name, address, phone, age, gender, created_at, updated_at FROM accounts WHERE id = $1`, userIdToFollow).Scan(&followedAccount.AccountID, &followedAccount.Email, &followedAccount.FirstName, &followedAccount.LastName, &followedAccount.Address, &followedAccount.Phone, &followedAccount.Age, &followedAccount.Gender, &createdAt, &updatedAt)
-	cancelQuery()
+	// To an account it blocked, the followed account looks like it does not exist.
+	blockedBy, err := r.Blocks.IsBlocking(ctx, userIDToFollow, currentUserID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user to follow not found")
-		}
-		log.Printf("FollowUser DB Error querying followed user %s: %v", userIdToFollow, err)
+		log.Printf("FollowUser DB Error checking block (%s -> %s): %v", userIDToFollow, currentUserID, err)
 		return nil, fmt.Errorf("internal server error")
 	}
-	followedAccount.CreatedAt = createdAt.Format(time.RFC3339)
-	if updatedAt.Valid {
-		formattedUpdatedAt := updatedAt.Time.Format(time.RFC3339)
-		followedAccount.UpdatedAt = &formattedUpdatedAt
-	} else {
-		followedAccount.UpdatedAt = nil
+	if blockedBy {
+		return nil, fmt.Errorf("user to follow not found")
+	}
+	blocking, err := r.Blocks.IsBlocking(ctx, currentUserID, userIDToFollow)
+	if err != nil {
+		log.Printf("FollowUser DB Error checking block (%s -> %s): %v", currentUserID, userIDToFollow, err)
+		return nil, fmt.Errorf("internal server error")
+	}
+	if blocking {
+		return nil, codedError(ctx, CodeForbidden, "unblock this account before following it")
+	}
+
+	if followedAccount.IsPrivate {
+		following, err := r.Follows.IsFollowing(ctx, currentUserID, userIDToFollow)
+		if err != nil {
+			log.Printf("FollowUser DB Error checking follow (%s -> %s): %v", currentUserID, userIDToFollow, err)
+			return nil, fmt.Errorf("internal server error")
+		}
+		if !following {
+			return r.requestFollow(ctx, currentUserID, followedAccount)
+		}
 	}
 
-	insertCtx, cancelInsert := context.WithTimeout(ctx, 5*time.Second)
-	result, err := db.ExecContext(insertCtx, `INSERT INTO follows (follower_user_id, followed_user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, currentUserID, userIdToFollow)
-	cancelInsert()
+	created, err := r.Follows.Follow(ctx, currentUserID, userIDToFollow)
 	if err != nil {
-		log.Printf("FollowUser DB Error inserting follow (%s -> %s): %v", currentUserID, userIdToFollow, err)
+		log.Printf("FollowUser DB Error inserting follow (%s -> %s): %v", currentUserID, userIDToFollow, err)
 		return nil, fmt.Errorf("failed to follow user")
 	}
+	log.Printf("User %s follow action for user %s (new follow: %v)", currentUserID, userIDToFollow, created)
 
-	rowsAffected, _ := result.RowsAffected()
-	log.Printf("User %s follow action for user %s (Rows affected: %d)", currentUserID, userIdToFollow, rowsAffected)
-
-	if rowsAffected > 0 {
-		log.Printf("New follow detected (%s -> %s), creating notification...", currentUserID, userIdToFollow)
-		go func(recipientID string, triggerID string) {
-			notifCtx, notifCancel := context.WithTimeout(context.Background(), 10*time.Second)
-			defer notifCancel()
-			dbNotif, errDb := getDB()
-			if errDb != nil {
-				log.Printf("FollowUser Notification Goroutine DB Error: %v", errDb)
-				return
-			}
-			defer dbNotif.Close()
-			_, errNotif := dbNotif.ExecContext(notifCtx, `INSERT INTO notifications (recipient_user_id, triggering_user_id, notification_type, entity_id, is_read, created_at) VALUES ($1, $2, $3, $4, $5, NOW())`, recipientID, triggerID, "new_follower", triggerID, false)
-			if errNotif != nil {
-				log.Printf("FollowUser: Failed to insert 'new_follower' notification for recipient %s: %v", recipientID, errNotif)
-			} else {
-				log.Printf("FollowUser: Inserted 'new_follower' notification for %s triggered by %s", recipientID, triggerID)
-			}
-		}(userIdToFollow, currentUserID)
+	if created {
+		r.followStarted(ctx, currentUserID, userIDToFollow)
 	} else {
-		log.Printf("User %s already follows %s or conflict occurred, no notification needed.", currentUserID, userIdToFollow)
+		log.Printf("User %s already follows %s or conflict occurred, no notification needed.", currentUserID, userIDToFollow)
 	}
 
-	return &followedAccount, nil
+	return followedAccount, nil
 }
 
 // UnfollowUser is the resolver for the unfollowUser field.
-func (r *mutationResolver) UnfollowUser(ctx context.Context, userIdToUnfollow string) (*model.Account, error) {
-	currentUserID, err := getCurrentUserID(ctx)
+func (r *mutationResolver) UnfollowUser(ctx context.Context, userIDToUnfollow string) (*model.Account, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
 	if err != nil {
-		log.Printf("UnfollowUser Error: Not authenticated: %v", err)
-		return nil, fmt.Errorf("authentication required")
+		return nil, err
 	}
 
-	db, err := getDB()
+	unfollowedAccount, err := r.Accounts.GetByID(ctx, userIDToUnfollow)
 	if err != nil {
-		log.Printf("UnfollowUser DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error")
+		log.Printf("UnfollowUser: Could not fetch unfollowed user %s, proceeding: %v", userIDToUnfollow, err)
+		unfollowedAccount = &model.Account{AccountID: userIDToUnfollow} // Use ID for return even if fetch failed
 	}
-	defer db.Close()
 
-	var unfollowedAccount model.Account
-	var createdAt time.Time
-	var updatedAt sql.NullTime
-	queryCtx, cancelQuery := context.WithTimeout(ctx, 5*time.Second)
-	err = db.QueryRowContext(queryCtx, `SELECT id, email, first_name, last_name, address, phone, age, gender, created_at, updated_at FROM accounts WHERE id = $1`, userIdToUnfollow).Scan(&unfollowedAccount.AccountID, &unfollowedAccount.Email, &unfollowedAccount.FirstName, &unfollowedAccount.LastName, &unfollowedAccount.Address, &unfollowedAccount.Phone, &unfollowedAccount.Age, &unfollowedAccount.Gender, &createdAt, &updatedAt)
-	cancelQuery()
+	removed, err := r.Follows.Unfollow(ctx, currentUserID, userIDToUnfollow)
 	if err != nil {
-		log.Printf("UnfollowUser: Could not fetch unfollowed user %s, proceeding: %v", userIdToUnfollow, err)
-		if err != sql.ErrNoRows {
-			log.Printf("UnfollowUser DB Error querying user %s: %v", userIdToUnfollow, err)
-		}
-		unfollowedAccount.AccountID = userIdToUnfollow // Use ID for return even if fetch failed
-	} else {
-		unfollowedAccount.CreatedAt = createdAt.Format(time.RFC3339)
-		if updatedAt.Valid {
-			formattedUpdatedAt := updatedAt.Time.Format(time.RFC3339)
-			unfollowedAccount.UpdatedAt = &formattedUpdatedAt
-		} else {
-			unfollowedAccount.UpdatedAt = nil
+		log.Printf("UnfollowUser DB Error deleting follow (%s -> %s): %v", currentUserID, userIDToUnfollow, err)
+		return nil, fmt.Errorf("failed to unfollow user")
+	}
+	log.Printf("User %s unfollowed user %s (removed: %v)", currentUserID, userIDToUnfollow, removed)
+	if removed {
+		r.SuggestionCache.Delete(currentUserID)
+		if err := r.Timelines.Prune(ctx, currentUserID, userIDToUnfollow); err != nil {
+			log.Printf("UnfollowUser: Failed to remove posts of %s from the timeline of %s: %v", userIDToUnfollow, currentUserID, err)
 		}
 	}
 
-	deleteCtx, cancelDelete := context.WithTimeout(ctx, 5*time.Second)
-	result, err := db.ExecContext(deleteCtx, `DELETE FROM follows WHERE follower_user_id = $1 AND followed_user_id = $2`, currentUserID, userIdToUnfollow)
-	cancelDelete()
+	return unfollowedAccount, nil
+}
+
+// SetAccountPrivacy is the resolver for the setAccountPrivacy field.
+func (r *mutationResolver) SetAccountPrivacy(ctx context.Context, isPrivate bool) (*model.Account, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
 	if err != nil {
-		log.Printf("UnfollowUser DB Error deleting follow (%s -> %s): %v", currentUserID, userIdToUnfollow, err)
-		return nil, fmt.Errorf("failed to unfollow user")
+		return nil, err
+	}
+	account, err := r.Accounts.SetPrivate(ctx, currentUserID, isPrivate)
+	if err != nil {
+		log.Printf("SetAccountPrivacy DB Error updating account %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to update account privacy")
+	}
+	log.Printf("Account %s is now private: %v", currentUserID, isPrivate)
+	if isPrivate {
+		return account, nil
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	log.Printf("User %s unfollowed user %s (Rows affected: %d)", currentUserID, userIdToUnfollow, rowsAffected)
+	// Nobody needs approval any more, so the waiting requesters become followers.
+	approved, err := r.FollowRequests.ApproveAll(ctx, currentUserID)
+	if err != nil {
+		log.Printf("SetAccountPrivacy DB Error approving follow requests of %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to approve pending follow requests")
+	}
+	for _, requesterID := range approved {
+		r.followStarted(ctx, requesterID, currentUserID)
+	}
+	return account, nil
+}
 
-	return &unfollowedAccount, nil
+// ApproveFollowRequest is the resolver for the approveFollowRequest field.
+func (r *mutationResolver) ApproveFollowRequest(ctx context.Context, requesterID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	approved, err := r.FollowRequests.Approve(ctx, requesterID, currentUserID)
+	if err != nil {
+		log.Printf("ApproveFollowRequest DB Error (%s -> %s): %v", requesterID, currentUserID, err)
+		return false, fmt.Errorf("failed to approve follow request")
+	}
+	if approved {
+		log.Printf("Follow request %s -> %s approved", requesterID, currentUserID)
+		r.followStarted(ctx, requesterID, currentUserID)
+	}
+	return approved, nil
 }
 
-// --- Query Resolvers ---
+// RejectFollowRequest is the resolver for the rejectFollowRequest field.
+func (r *mutationResolver) RejectFollowRequest(ctx context.Context, requesterID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return false, err
+	}
+	rejected, err := r.FollowRequests.Delete(ctx, requesterID, currentUserID)
+	if err != nil {
+		log.Printf("RejectFollowRequest DB Error (%s -> %s): %v", requesterID, currentUserID, err)
+		return false, fmt.Errorf("failed to reject follow request")
+	}
+	return rejected, nil
+}
 
-// GetAccount is the resolver for the getAccount field.
-func (r *queryResolver) GetAccount(ctx context.Context, accountID string) (*model.Account, error) {
-	db, err := getDB()
+// CancelFollowRequest is the resolver for the cancelFollowRequest field.
+func (r *mutationResolver) CancelFollowRequest(ctx context.Context, targetID string) (bool, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
 	if err != nil {
-		log.Printf("GetAccount DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error")
+		return false, err
+	}
+	cancelled, err := r.FollowRequests.Delete(ctx, currentUserID, targetID)
+	if err != nil {
+		log.Printf("CancelFollowRequest DB Error (%s -> %s): %v", currentUserID, targetID, err)
+		return false, fmt.Errorf("failed to cancel follow request")
 	}
-	defer db.Close()
+	if cancelled {
+		r.SuggestionCache.Delete(currentUserID)
+	}
+	return cancelled, nil
+}
 
-	var account model.Account
-	var createdAt time.Time
-	var updatedAt sql.NullTime
-	queryCtx, cancelQuery := context.WithTimeout(ctx, 5*time.Second)
-	defer cancelQuery()
-	err = db.QueryRowContext(queryCtx, `SELECT id, email, first_name, last_name, address, phone, age, gender, created_at, updated_at FROM accounts WHERE id = $1`, accountID).Scan(&account.AccountID, &account.Email, &account.FirstName, &account.LastName, &account.Address, &account.Phone, &account.Age, &account.Gender, &createdAt, &updatedAt)
+// --- Query Resolvers ---
 
+// GetAccount is the resolver for the getAccount field.
+func (r *queryResolver) GetAccount(ctx context.Context, accountID string) (*model.Account, error) {
+	account, err := r.Accounts.GetByID(ctx, accountID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, store.ErrNotFound) {
 			return nil, fmt.Errorf("account not found")
 		} // Return specific error
 		log.Printf("GetAccount DB Error querying account %s: %v", accountID, err)
 		return nil, fmt.Errorf("internal server error")
 	}
-
-	account.CreatedAt = createdAt.Format(time.RFC3339)
-	if updatedAt.Valid {
-		formattedUpdatedAt := updatedAt.Time.Format(time.RFC3339)
-		account.UpdatedAt = &formattedUpdatedAt
-	} else {
-		account.UpdatedAt = nil
+	// Accounts that blocked the viewer look like they do not exist; the viewer blocking them does not hide them.
+	if viewerID, _ := getCurrentUserID(ctx); viewerID != "" {
+		blocked, err := r.Blocks.IsBlocking(ctx, accountID, viewerID)
+		if err != nil {
+			log.Printf("GetAccount DB Error checking blocks (%s -> %s): %v", accountID, viewerID, err)
+			return nil, fmt.Errorf("internal server error")
+		}
+		if blocked {
+			return nil, fmt.Errorf("account not found")
+		}
 	}
 
 	// Note: The Account.IsFollowing field is resolved by the accountResolver.IsFollowing method
-	return &account, nil
+	return account, nil
 }
 
 // ListAccounts is the resolver for the listAccounts field.
-func (r *queryResolver) ListAccounts(ctx context.Context) ([]*model.Account, error) {
-	db, err := getDB()
+func (r *queryResolver) ListAccounts(ctx context.Context, first *int32, after *string) (*model.AccountConnection, error) {
+	limit, cursor, err := pageArgs(ctx, first, after)
 	if err != nil {
-		log.Printf("ListAccounts DB Error: %v", err)
-		return nil, fmt.Errorf("internal server error")
+		return nil, err
 	}
-	defer db.Close()
-
-	queryCtx, cancelQuery := context.WithTimeout(ctx, 10*time.Second)
-	defer cancelQuery()
-	rows, err := db.QueryContext(queryCtx, `SELECT id, email, first_name, last_name, address, phone, age, gender, created_at, updated_at FROM accounts ORDER BY created_at DESC`)
-
+	edges, err := r.Accounts.List(ctx, cursor, limit)
 	if err != nil {
 		log.Printf("ListAccounts DB Error querying: %v", err)
 		return nil, fmt.Errorf("failed to list accounts")
 	}
-	defer rows.Close()
+	// Note: The Account.IsFollowing field is resolved by the accountResolver.IsFollowing method for each account if requested in the query
+	return accountConnection(edges, limit), nil
+}
 
-	accounts := []*model.Account{}
-	for rows.Next() {
-		var acc model.Account
-		var createdAt time.Time
-		var updatedAt sql.NullTime
-		err := rows.Scan(&acc.AccountID, &acc.Email, &acc.FirstName, &acc.LastName, &acc.Address, &acc.Phone, &acc.Age, &acc.Gender, &createdAt, &updatedAt)
+// SuggestedAccounts is the resolver for the suggestedAccounts field.
+func (r *queryResolver) SuggestedAccounts(ctx context.Context, first *int32) ([]*model.SuggestedAccount, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultSuggestions
+	if first != nil {
+		if *first < 0 || *first > maxSuggestions {
+			return nil, codedError(ctx, CodeBadUserInput, fmt.Sprintf("first must be between 0 and %d", maxSuggestions))
+		}
+		limit = int(*first)
+	}
+
+	// The full ranking is cached so every page size is served from one entry.
+	suggestions, ok := r.SuggestionCache.Get(currentUserID)
+	if !ok {
+		suggestions, err = r.Follows.Suggest(ctx, currentUserID, maxSuggestions)
 		if err != nil {
-			log.Printf("ListAccounts DB Error scanning row: %v", err)
-			continue
+			log.Printf("SuggestedAccounts DB Error for user %s: %v", currentUserID, err)
+			return nil, fmt.Errorf("failed to suggest accounts")
 		}
-		acc.CreatedAt = createdAt.Format(time.RFC3339)
-		if updatedAt.Valid {
-			formattedUpdatedAt := updatedAt.Time.Format(time.RFC3339)
-			acc.UpdatedAt = &formattedUpdatedAt
-		} else {
-			acc.UpdatedAt = nil
+		r.SuggestionCache.Set(currentUserID, suggestions)
+	}
+	suggestions = suggestions[:min(limit, len(suggestions))]
+
+	// Accounts are loaded fresh; only the ranking may be stale.
+	var ids []string
+	for _, s := range suggestions {
+		ids = append(ids, s.AccountID)
+		ids = append(ids, s.MutualIDs...)
+	}
+	accounts, err := r.Accounts.GetByIDs(ctx, ids)
+	if err != nil {
+		log.Printf("SuggestedAccounts DB Error loading accounts: %v", err)
+		return nil, fmt.Errorf("failed to suggest accounts")
+	}
+	result := make([]*model.SuggestedAccount, 0, len(suggestions))
+	for _, s := range suggestions {
+		account, ok := accounts[s.AccountID]
+		if !ok {
+			continue // Deleted since the ranking was cached
+		}
+		suggestion := &model.SuggestedAccount{Account: account, MutualCount: int32(s.MutualCount), MutualFollowers: []*model.Account{}}
+		for _, id := range s.MutualIDs {
+			if acc, ok := accounts[id]; ok {
+				suggestion.MutualFollowers = append(suggestion.MutualFollowers, acc)
+			}
 		}
-		// Note: The Account.IsFollowing field is resolved by the accountResolver.IsFollowing method for each account if requested in the query
-		accounts = append(accounts, &acc)
+		result = append(result, suggestion)
+	}
+	return result, nil
+}
+
+// PendingFollowRequests is the resolver for the pendingFollowRequests field.
+func (r *queryResolver) PendingFollowRequests(ctx context.Context, first *int32, after *string) (*model.FollowRequestConnection, error) {
+	currentUserID, err := getCurrentUserID(ctx) // @auth guarantees a user
+	if err != nil {
+		return nil, err
+	}
+	limit, cursor, err := pageArgs(ctx, first, after)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := r.FollowRequests.ListPending(ctx, currentUserID, cursor, limit)
+	if err != nil {
+		log.Printf("PendingFollowRequests DB Error for user %s: %v", currentUserID, err)
+		return nil, fmt.Errorf("failed to fetch follow requests")
+	}
+	edges, pageInfo := trimPage(edges, limit, func(e *model.FollowRequestEdge) string { return e.Cursor })
+	return &model.FollowRequestConnection{Edges: edges, PageInfo: pageInfo}, nil
+}
+
+// Account returns AccountResolver implementation.
+func (r *Resolver) Account() AccountResolver { return &accountResolver{r} }
+
+type accountResolver struct{ *Resolver }
+
+// followCounts returns accountID's maintained follow counters.
+func (r *Resolver) followCounts(ctx context.Context, accountID string) (store.FollowCounts, error) {
+	counts, err := r.dataLoaders(ctx).FollowCounts.Load(ctx, accountID)
+	if err != nil {
+		log.Printf("followCounts DB Error loading counts of %s: %v", accountID, err)
+		return store.FollowCounts{}, fmt.Errorf("failed to fetch follow counts")
+	}
+	return counts, nil
+}
+
+// accountConnection trims a page of accounts fetched with a limit from pageArgs.
+func accountConnection(edges []*model.AccountEdge, limit int) *model.AccountConnection {
+	if edges == nil {
+		edges = []*model.AccountEdge{}
 	}
-	if err = rows.Err(); err != nil {
-		log.Printf("ListAccounts DB Error iterating rows: %v", err)
-		return nil, fmt.Errorf("error reading accounts list")
+	edges, pageInfo := trimPage(edges, limit, func(e *model.AccountEdge) string { return e.Cursor })
+	return &model.AccountConnection{Edges: edges, PageInfo: pageInfo}
+}
+
+// requestFollow asks the private account target to approve followerID, notifying its owner of new requests.
+func (r *Resolver) requestFollow(ctx context.Context, followerID string, target *model.Account) (*model.Account, error) {
+	created, err := r.FollowRequests.Create(ctx, followerID, target.AccountID)
+	if err != nil {
+		log.Printf("FollowUser DB Error inserting follow request (%s -> %s): %v", followerID, target.AccountID, err)
+		return nil, fmt.Errorf("failed to request follow")
+	}
+	log.Printf("User %s requested to follow private account %s (new request: %v)", followerID, target.AccountID, created)
+	if created {
+		r.SuggestionCache.Delete(followerID)
+		go func(recipientID string, requesterID string) {
+			notifCtx, notifCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer notifCancel()
+			r.sendNotifications(notifCtx, model.NotificationTypeFollowRequest, requesterID, requesterID, []string{recipientID}, time.Now())
+		}(target.AccountID, followerID)
 	}
+	return target, nil
+}
 
-	return accounts, nil
+// followStarted backfills the new follower's timeline and notifies the followed account. Call it once per
+// follow, after it was stored.
+func (r *Resolver) followStarted(ctx context.Context, followerID, followedID string) {
+	r.SuggestionCache.Delete(followerID)
+	// Their recent posts should show up in the feed right away, not only their next ones.
+	if err := r.Timelines.Backfill(ctx, followerID, followedID, r.Feed.FanoutMaxFollowers, r.Feed.BackfillPosts); err != nil {
+		log.Printf("FollowUser: Failed to backfill timeline of %s with posts of %s: %v", followerID, followedID, err)
+	}
+
+	log.Printf("New follow detected (%s -> %s), creating notification...", followerID, followedID)
+	go func(recipientID string, triggerID string) {
+		notifCtx, notifCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer notifCancel()
+		if r.sendNotifications(notifCtx, model.NotificationTypeNewFollower, triggerID, triggerID, []string{recipientID}, time.Now()) > 0 {
+			log.Printf("FollowUser: Inserted 'new_follower' notification for %s triggered by %s", recipientID, triggerID)
+		}
+	}(followedID, followerID)
 }
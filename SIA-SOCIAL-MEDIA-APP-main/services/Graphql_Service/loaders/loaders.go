@@ -0,0 +1,205 @@
+// Package loaders provides operation-scoped batch loaders so resolvers for fields on list items issue one
+// query per field instead of one per item.
+//
+// Extension attaches a fresh set of loaders to every operation; resolvers fetch them with For.
+package loaders
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+const (
+	// batchWait is how long a loader collects keys before querying. Sibling fields of a list are
+	// resolved concurrently, so a short window is enough to catch all of them.
+	batchWait = 2 * time.Millisecond
+	// maxBatchSize caps the keys sent in one query.
+	maxBatchSize = 500
+)
+
+// FollowKey asks whether FollowerID follows FollowedID.
+type FollowKey struct {
+	FollowerID string
+	FollowedID string
+}
+
+// ReactionKey asks for AccountID's reaction to Target.
+type ReactionKey struct {
+	Target    store.ReactionTarget
+	AccountID string
+}
+
+// Loaders is the set of batch loaders for one operation.
+type Loaders struct {
+	// Accounts loads accounts by ID; unknown IDs load as nil.
+	Accounts *Loader[string, *model.Account]
+	// Follows loads follow state by (follower, followed) pair, batched per follower.
+	Follows *Loader[FollowKey, bool]
+	// FollowedBy loads follow state like Follows, batched per followed account, for "follows you" lookups.
+	FollowedBy *Loader[FollowKey, bool]
+	// FollowRequests loads whether a follow request is pending by (requester, target) pair, batched per requester.
+	FollowRequests *Loader[FollowKey, bool]
+	// FollowCounts loads accounts' follower and following counts.
+	FollowCounts *Loader[string, store.FollowCounts]
+	// ReactionCounts loads the number of accounts per reaction on a post or comment.
+	ReactionCounts *Loader[store.ReactionTarget, map[string]int]
+	// Reactions loads an account's reaction to a post or comment, or "" if it has none.
+	Reactions *Loader[ReactionKey, string]
+}
+
+type ctxKey struct{}
+
+// New returns loaders reading from repos whose batches run with ctx.
+func New(ctx context.Context, repos store.Repositories) *Loaders {
+	return &Loaders{
+		Accounts: NewLoader(ctx, func(ctx context.Context, ids []string) (map[string]*model.Account, error) {
+			return repos.Accounts.GetByIDs(ctx, ids)
+		}, batchWait, maxBatchSize),
+		Follows:        NewLoader(ctx, followLoader(repos.Follows), batchWait, maxBatchSize),
+		FollowedBy:     NewLoader(ctx, followedByLoader(repos.Follows), batchWait, maxBatchSize),
+		FollowRequests: NewLoader(ctx, followRequestLoader(repos.FollowRequests), batchWait, maxBatchSize),
+		FollowCounts: NewLoader(ctx, func(ctx context.Context, ids []string) (map[string]store.FollowCounts, error) {
+			return repos.Follows.Counts(ctx, ids)
+		}, batchWait, maxBatchSize),
+		ReactionCounts: NewLoader(ctx, reactionCountLoader(repos.Reactions), batchWait, maxBatchSize),
+		Reactions:      NewLoader(ctx, reactionLoader(repos.Reactions), batchWait, maxBatchSize),
+	}
+}
+
+// Extension is a gqlgen handler extension giving every response its own loaders, so cached values never
+// outlive it. A query or mutation has one response; a subscription has one per event, so loaders are not
+// shared across the events of a long-lived websocket operation.
+type Extension struct {
+	Repos store.Repositories
+}
+
+var (
+	_ graphql.HandlerExtension    = Extension{}
+	_ graphql.ResponseInterceptor = Extension{}
+)
+
+func (Extension) ExtensionName() string { return "DataLoaders" }
+
+func (Extension) Validate(graphql.ExecutableSchema) error { return nil }
+
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	return next(context.WithValue(ctx, ctxKey{}, New(ctx, e.Repos)))
+}
+
+// For returns the loaders attached by Extension, or nil.
+func For(ctx context.Context) *Loaders {
+	l, _ := ctx.Value(ctxKey{}).(*Loaders)
+	return l
+}
+
+// followLoader queries follow state once per follower; in practice every key has the viewer as follower.
+func followLoader(follows store.FollowRepository) func(context.Context, []FollowKey) (map[FollowKey]bool, error) {
+	return func(ctx context.Context, keys []FollowKey) (map[FollowKey]bool, error) {
+		byFollower := map[string][]string{}
+		for _, k := range keys {
+			byFollower[k.FollowerID] = append(byFollower[k.FollowerID], k.FollowedID)
+		}
+		state := make(map[FollowKey]bool, len(keys))
+		for followerID, followedIDs := range byFollower {
+			followed, err := follows.FollowedAmong(ctx, followerID, followedIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range followedIDs {
+				state[FollowKey{followerID, id}] = followed[id]
+			}
+		}
+		return state, nil
+	}
+}
+
+// followedByLoader queries follow state once per followed account; in practice every key has the viewer as
+// the followed account.
+func followedByLoader(follows store.FollowRepository) func(context.Context, []FollowKey) (map[FollowKey]bool, error) {
+	return func(ctx context.Context, keys []FollowKey) (map[FollowKey]bool, error) {
+		byFollowed := map[string][]string{}
+		for _, k := range keys {
+			byFollowed[k.FollowedID] = append(byFollowed[k.FollowedID], k.FollowerID)
+		}
+		state := make(map[FollowKey]bool, len(keys))
+		for followedID, followerIDs := range byFollowed {
+			followers, err := follows.FollowersAmong(ctx, followedID, followerIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range followerIDs {
+				state[FollowKey{id, followedID}] = followers[id]
+			}
+		}
+		return state, nil
+	}
+}
+
+// followRequestLoader queries pending requests once per requester, like followLoader.
+func followRequestLoader(requests store.FollowRequestRepository) func(context.Context, []FollowKey) (map[FollowKey]bool, error) {
+	return func(ctx context.Context, keys []FollowKey) (map[FollowKey]bool, error) {
+		byRequester := map[string][]string{}
+		for _, k := range keys {
+			byRequester[k.FollowerID] = append(byRequester[k.FollowerID], k.FollowedID)
+		}
+		state := make(map[FollowKey]bool, len(keys))
+		for requesterID, targetIDs := range byRequester {
+			requested, err := requests.RequestedAmong(ctx, requesterID, targetIDs)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range targetIDs {
+				state[FollowKey{requesterID, id}] = requested[id]
+			}
+		}
+		return state, nil
+	}
+}
+
+// reactionCountLoader queries counts once per target kind.
+func reactionCountLoader(reactions store.ReactionRepository) func(context.Context, []store.ReactionTarget) (map[store.ReactionTarget]map[string]int, error) {
+	return func(ctx context.Context, targets []store.ReactionTarget) (map[store.ReactionTarget]map[string]int, error) {
+		byKind := map[string][]string{}
+		for _, t := range targets {
+			byKind[t.Kind] = append(byKind[t.Kind], t.ID)
+		}
+		counts := make(map[store.ReactionTarget]map[string]int, len(targets))
+		for kind, ids := range byKind {
+			found, err := reactions.Counts(ctx, kind, ids)
+			if err != nil {
+				return nil, err
+			}
+			for id, c := range found {
+				counts[store.ReactionTarget{Kind: kind, ID: id}] = c
+			}
+		}
+		return counts, nil
+	}
+}
+
+// reactionLoader queries reactions once per (target kind, account).
+func reactionLoader(reactions store.ReactionRepository) func(context.Context, []ReactionKey) (map[ReactionKey]string, error) {
+	return func(ctx context.Context, keys []ReactionKey) (map[ReactionKey]string, error) {
+		type group struct{ kind, accountID string }
+		groups := map[group][]string{}
+		for _, k := range keys {
+			g := group{k.Target.Kind, k.AccountID}
+			groups[g] = append(groups[g], k.Target.ID)
+		}
+		found := make(map[ReactionKey]string, len(keys))
+		for g, ids := range groups {
+			byID, err := reactions.ForAccount(ctx, g.kind, ids, g.accountID)
+			if err != nil {
+				return nil, err
+			}
+			for id, reaction := range byID {
+				found[ReactionKey{store.ReactionTarget{Kind: g.kind, ID: id}, g.accountID}] = reaction
+			}
+		}
+		return found, nil
+	}
+}
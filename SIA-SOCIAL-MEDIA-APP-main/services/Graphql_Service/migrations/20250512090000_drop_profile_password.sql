@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Credentials live on accounts; profiles only carry public-facing details.
+ALTER TABLE profiles DROP COLUMN password;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE profiles ADD COLUMN password VARCHAR(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Roles granted to accounts, checked by the @hasRole directive alongside roles carried in token claims.
+CREATE TABLE account_roles (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL CHECK (role = lower(role)),
+    granted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, role)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE account_roles;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Posts are soft-deleted so notifications and revisions keep pointing at a real row.
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- One row per version of a post that an edit replaced.
+CREATE TABLE post_revisions (
+    revision_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL, -- when this version was written
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_post_revisions_post_id ON post_revisions(post_id, replaced_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE post_revisions;
+ALTER TABLE posts DROP COLUMN deleted_at;
+-- +goose StatementEnd
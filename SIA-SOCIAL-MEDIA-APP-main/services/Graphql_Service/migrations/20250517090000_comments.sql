@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Comments and replies. parent_comment_id is NULL for top-level comments; replies belong to the same post.
+-- Deleted comments are kept (deleted_at set) so replies stay attached to the thread.
+CREATE TABLE comments (
+    comment_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id UUID NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    parent_comment_id UUID REFERENCES comments(comment_id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_comments_thread ON comments(post_id, parent_comment_id, created_at, comment_id);
+CREATE INDEX idx_comments_live_post ON comments(post_id) WHERE deleted_at IS NULL;
+
+ALTER TABLE notifications DROP CONSTRAINT notifications_notification_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notifications WHERE notification_type = 'comment_reply';
+ALTER TABLE notifications DROP CONSTRAINT notifications_notification_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'like'));
+DROP TABLE comments;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One reaction per account and post/comment. target_id points at posts.post_id or comments.comment_id
+-- depending on target_kind.
+CREATE TABLE reactions (
+    target_kind VARCHAR(10) NOT NULL CHECK (target_kind IN ('post', 'comment')),
+    target_id UUID NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    reaction VARCHAR(20) NOT NULL CHECK (reaction IN ('like', 'love', 'haha', 'wow', 'sad', 'angry')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (target_kind, target_id, account_id)
+);
+
+-- Counters maintained alongside reactions so feeds can show totals without aggregating.
+CREATE TABLE reaction_counts (
+    target_kind VARCHAR(10) NOT NULL,
+    target_id UUID NOT NULL,
+    reaction VARCHAR(20) NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0 CHECK (count >= 0),
+    PRIMARY KEY (target_kind, target_id, reaction)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE reaction_counts;
+DROP TABLE reactions;
+-- +goose StatementEnd
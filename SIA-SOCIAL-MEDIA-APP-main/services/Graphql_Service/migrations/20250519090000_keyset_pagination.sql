@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Connections page by (created_at, id), newest first; keyset comparisons need created_at to be set.
+UPDATE notifications SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE notifications ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_posts_live_recent ON posts(created_at DESC, post_id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_posts_author_recent ON posts(author_id, created_at DESC, post_id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_notifications_recipient_recent ON notifications(recipient_user_id, created_at DESC, notification_id DESC);
+CREATE INDEX idx_accounts_recent ON accounts(created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_accounts_recent;
+DROP INDEX idx_notifications_recipient_recent;
+DROP INDEX idx_posts_author_recent;
+DROP INDEX idx_posts_live_recent;
+ALTER TABLE notifications ALTER COLUMN created_at DROP NOT NULL;
+-- +goose StatementEnd
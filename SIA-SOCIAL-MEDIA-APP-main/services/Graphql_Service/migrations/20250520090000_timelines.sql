@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Follower counts decide whether an author's posts are fanned out on write or merged into feeds on read.
+ALTER TABLE accounts ADD COLUMN follower_count INTEGER NOT NULL DEFAULT 0;
+UPDATE accounts a SET follower_count = (SELECT COUNT(*) FROM follows f WHERE f.followed_user_id = a.id);
+
+-- Home timelines: one row per (follower, post). created_at copies the post's so feeds page by (created_at, post_id).
+CREATE TABLE timelines (
+    owner_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(post_id) ON DELETE CASCADE,
+    author_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (owner_id, post_id)
+);
+CREATE INDEX idx_timelines_owner_recent ON timelines(owner_id, created_at DESC, post_id DESC);
+CREATE INDEX idx_timelines_owner_author ON timelines(owner_id, author_id);
+CREATE INDEX idx_follows_followed ON follows(followed_user_id);
+
+INSERT INTO timelines (owner_id, post_id, author_id, created_at)
+SELECT f.follower_user_id, p.post_id, p.author_id, p.created_at
+FROM follows f JOIN posts p ON p.author_id = f.followed_user_id AND p.deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_follows_followed;
+DROP TABLE timelines;
+ALTER TABLE accounts DROP COLUMN follower_count;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every new notification is announced on the notification_added channel. Each server replica LISTENs on it
+-- and pushes the notification to its own notificationAdded subscribers.
+CREATE FUNCTION notify_notification_added() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('notification_added', json_build_object(
+        'notification_id', NEW.notification_id,
+        'recipient_user_id', NEW.recipient_user_id
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notifications_notify
+AFTER INSERT ON notifications
+FOR EACH ROW EXECUTE FUNCTION notify_notification_added();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER notifications_notify ON notifications;
+DROP FUNCTION notify_notification_added();
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE notifications SET is_read = false WHERE is_read IS NULL;
+ALTER TABLE notifications ALTER COLUMN is_read SET NOT NULL;
+CREATE INDEX idx_notifications_recipient_unread ON notifications(recipient_user_id, created_at) WHERE is_read = false;
+
+-- Announces recipients whose notifications were marked read so every replica can push the new unread count.
+-- The payload only names the recipient, and Postgres folds identical payloads sent in one transaction,
+-- so marking many notifications read sends a single event.
+CREATE FUNCTION notify_notification_read() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('notification_read', json_build_object('recipient_user_id', NEW.recipient_user_id)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER notifications_read_notify
+AFTER UPDATE OF is_read ON notifications
+FOR EACH ROW WHEN (NEW.is_read AND NOT OLD.is_read)
+EXECUTE FUNCTION notify_notification_read();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER notifications_read_notify ON notifications;
+DROP FUNCTION notify_notification_read();
+DROP INDEX idx_notifications_recipient_unread;
+ALTER TABLE notifications ALTER COLUMN is_read DROP NOT NULL;
+-- +goose StatementEnd
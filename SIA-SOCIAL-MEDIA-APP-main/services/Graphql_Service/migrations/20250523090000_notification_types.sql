@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Align the allowed values with the GraphQL NotificationType enum (lower-cased). 'new_follower' was still
+-- rejected by the constraint, so follow notifications were never stored.
+ALTER TABLE notifications DROP CONSTRAINT notifications_notification_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like', 'new_follower'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notifications WHERE notification_type = 'new_follower';
+ALTER TABLE notifications DROP CONSTRAINT notifications_notification_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like'));
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-type delivery channels. Types without a row use the defaults (in-app only).
+CREATE TABLE notification_preferences (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    notification_type VARCHAR(50) NOT NULL
+        CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like', 'new_follower')),
+    in_app BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    webhook BOOLEAN NOT NULL,
+    PRIMARY KEY (account_id, notification_type)
+);
+
+CREATE TABLE notification_settings (
+    account_id UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    webhook_url TEXT
+);
+
+-- Authors whose new posts an account no longer wants to hear about.
+CREATE TABLE notification_mutes (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    muted_account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, muted_account_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE notification_mutes;
+DROP TABLE notification_settings;
+DROP TABLE notification_preferences;
+-- +goose StatementEnd
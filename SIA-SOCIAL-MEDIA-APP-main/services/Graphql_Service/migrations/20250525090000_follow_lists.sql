@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- follower_count is already maintained by follow/unfollow; following_count joins it so profiles never COUNT(*).
+ALTER TABLE accounts ADD COLUMN following_count INTEGER NOT NULL DEFAULT 0;
+UPDATE accounts a SET following_count = (SELECT COUNT(*) FROM follows f WHERE f.follower_user_id = a.id);
+
+-- Follower and following lists page by (follow time, account id), newest follow first.
+UPDATE follows SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE follows ALTER COLUMN created_at SET NOT NULL;
+CREATE INDEX idx_follows_followers_recent ON follows(followed_user_id, created_at DESC, follower_user_id DESC);
+CREATE INDEX idx_follows_following_recent ON follows(follower_user_id, created_at DESC, followed_user_id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_follows_following_recent;
+DROP INDEX idx_follows_followers_recent;
+ALTER TABLE follows ALTER COLUMN created_at DROP NOT NULL;
+ALTER TABLE accounts DROP COLUMN following_count;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Private accounts approve each follower; their posts are only shown to those followers.
+ALTER TABLE accounts ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE follow_requests (
+    requester_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    target_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (requester_id, target_id)
+);
+CREATE INDEX idx_follow_requests_target_recent ON follow_requests(target_id, created_at DESC, requester_id DESC);
+
+ALTER TABLE notifications DROP CONSTRAINT notifications_notification_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like', 'new_follower', 'follow_request'));
+ALTER TABLE notification_preferences DROP CONSTRAINT notification_preferences_notification_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like', 'new_follower', 'follow_request'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM notification_preferences WHERE notification_type = 'follow_request';
+ALTER TABLE notification_preferences DROP CONSTRAINT notification_preferences_notification_type_check;
+ALTER TABLE notification_preferences ADD CONSTRAINT notification_preferences_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like', 'new_follower'));
+DELETE FROM notifications WHERE notification_type = 'follow_request';
+ALTER TABLE notifications DROP CONSTRAINT notifications_notification_type_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_notification_type_check
+    CHECK (notification_type IN ('new_post', 'new_comment', 'comment_reply', 'like', 'new_follower'));
+DROP TABLE follow_requests;
+ALTER TABLE accounts DROP COLUMN is_private;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Blocks cut both accounts off from each other; mutes only hide the muted account from the muter.
+CREATE TABLE account_blocks (
+    blocker_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+CREATE INDEX idx_account_blocks_blocked ON account_blocks(blocked_id);
+CREATE INDEX idx_account_blocks_recent ON account_blocks(blocker_id, created_at DESC, blocked_id DESC);
+
+CREATE TABLE account_mutes (
+    muter_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    muted_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (muter_id, muted_id)
+);
+CREATE INDEX idx_account_mutes_recent ON account_mutes(muter_id, created_at DESC, muted_id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE account_mutes;
+DROP TABLE account_blocks;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Conversations are direct (two members, one per pair through direct_key) or small groups.
+CREATE TABLE conversations (
+    conversation_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    is_group BOOLEAN NOT NULL,
+    title TEXT,
+    -- The two member IDs of a direct conversation in ascending order, joined by ':'; NULL for groups.
+    direct_key TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_message_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The read position is the newest message a member has read; read receipts are derived from it.
+CREATE TABLE conversation_members (
+    conversation_id UUID NOT NULL REFERENCES conversations(conversation_id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_read_message_id UUID,
+    last_read_at TIMESTAMPTZ,
+    PRIMARY KEY (conversation_id, account_id)
+);
+CREATE INDEX idx_conversation_members_account ON conversation_members(account_id);
+
+-- Deleted messages keep their row (body NULL, deleted_at set) as a placeholder in the history.
+CREATE TABLE messages (
+    message_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID NOT NULL REFERENCES conversations(conversation_id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    body TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    edited_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+CREATE INDEX idx_messages_conversation_recent ON messages(conversation_id, created_at DESC, message_id DESC);
+
+-- Every sent, edited or deleted message is announced on the message_changed channel. Each server replica
+-- LISTENs on it and pushes the message to its own messageReceived subscribers.
+CREATE FUNCTION notify_message_changed() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('message_changed', json_build_object(
+        'message_id', NEW.message_id,
+        'conversation_id', NEW.conversation_id,
+        'sender_id', NEW.sender_id
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER messages_notify
+AFTER INSERT OR UPDATE ON messages
+FOR EACH ROW EXECUTE FUNCTION notify_message_changed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER messages_notify ON messages;
+DROP FUNCTION notify_message_changed();
+DROP TABLE messages;
+DROP TABLE conversation_members;
+DROP TABLE conversations;
+-- +goose StatementEnd
@@ -0,0 +1,99 @@
+// Package mq publishes JSON messages to durable RabbitMQ queues consumed by the service's background workers.
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+)
+
+// Publisher keeps one connection to RabbitMQ, opened on first use and reopened after a failed publish.
+// A nil *Publisher is valid and publishes nothing, for deployments without RABBITMQ_URL.
+type Publisher struct {
+	url string
+
+	mu       sync.Mutex
+	conn     *amqp091.Connection
+	ch       *amqp091.Channel
+	declared map[string]bool
+}
+
+// NewPublisher returns a Publisher for url, or nil when url is empty.
+func NewPublisher(url string) *Publisher {
+	if url == "" {
+		return nil
+	}
+	return &Publisher{url: url, declared: map[string]bool{}}
+}
+
+// Publish sends msg, encoded as JSON, to the durable queue named queue. It reports whether the message was
+// handed to RabbitMQ; a nil Publisher returns (false, nil).
+func (p *Publisher) Publish(ctx context.Context, queue string, msg any) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, fmt.Errorf("mq: encode %s message: %w", queue, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ch, err := p.channel()
+	if err != nil {
+		return false, err
+	}
+	if !p.declared[queue] {
+		if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+			p.reset()
+			return false, fmt.Errorf("mq: declare queue %s: %w", queue, err)
+		}
+		p.declared[queue] = true
+	}
+	err = ch.PublishWithContext(ctx, "", queue, false, false, amqp091.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp091.Persistent,
+		Body:         body,
+		Timestamp:    time.Now(),
+	})
+	if err != nil {
+		p.reset()
+		return false, fmt.Errorf("mq: publish to %s: %w", queue, err)
+	}
+	return true, nil
+}
+
+// channel returns the open channel, connecting first if needed. Callers hold mu.
+func (p *Publisher) channel() (*amqp091.Channel, error) {
+	if p.conn != nil && !p.conn.IsClosed() && p.ch != nil && !p.ch.IsClosed() {
+		return p.ch, nil
+	}
+	p.reset()
+	conn, err := amqp091.Dial(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("mq: connect: %w", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("mq: open channel: %w", err)
+	}
+	p.conn, p.ch = conn, ch
+	return ch, nil
+}
+
+// reset drops the connection so the next publish reconnects. Callers hold mu.
+func (p *Publisher) reset() {
+	if p.ch != nil {
+		p.ch.Close()
+	}
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn, p.ch = nil, nil
+	p.declared = map[string]bool{}
+}
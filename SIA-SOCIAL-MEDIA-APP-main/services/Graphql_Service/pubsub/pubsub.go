@@ -0,0 +1,67 @@
+// Package pubsub fans events out to the GraphQL subscriptions served by this process.
+//
+// A Hub only reaches subscribers in the same process; the stores feed it from a source every replica
+// sees (Postgres LISTEN/NOTIFY), so a subscriber on any replica receives events published on any other.
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before further events are dropped for it.
+const subscriberBuffer = 16
+
+// Hub delivers events published under a key, typically a recipient's account ID, to that key's subscribers.
+type Hub[T any] struct {
+	mu   sync.Mutex
+	subs map[string]map[chan T]struct{}
+}
+
+// NewHub returns a Hub without subscribers.
+func NewHub[T any]() *Hub[T] {
+	return &Hub[T]{subs: map[string]map[chan T]struct{}{}}
+}
+
+// Subscribe returns a channel receiving the events published under key until ctx is done, after which the
+// channel is closed.
+func (h *Hub[T]) Subscribe(ctx context.Context, key string) <-chan T {
+	ch := make(chan T, subscriberBuffer)
+	h.mu.Lock()
+	if h.subs[key] == nil {
+		h.subs[key] = map[chan T]struct{}{}
+	}
+	h.subs[key][ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[key], ch)
+		if len(h.subs[key]) == 0 {
+			delete(h.subs, key)
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// HasSubscribers reports whether anyone is subscribed to key, so publishers can skip building the event.
+func (h *Hub[T]) HasSubscribers(key string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[key]) > 0
+}
+
+// Publish delivers v to every subscriber of key without blocking. A subscriber whose buffer is full misses v.
+func (h *Hub[T]) Publish(key string, v T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[key] {
+		select {
+		case ch <- v:
+		default:
+		}
+	}
+}
@@ -2,12 +2,18 @@ package main
 
 import (
 	"context" // Import context package
-	"fmt"     // Import fmt for errors
+	"graphql/auth"
+	"graphql/cache"
+	"graphql/config"
 	"graphql/graph"
+	"graphql/loaders"
+	"graphql/mq"
+	"graphql/store"
+	"graphql/store/postgres"
 	"log"
 	"net/http"
-	"os"
 	"strings" // Import strings package
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
@@ -15,80 +21,103 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/golang-jwt/jwt/v5" // Import JWT library
+	"github.com/gorilla/websocket"
 	"github.com/joho/godotenv"
 	"github.com/rs/cors" // Import CORS package
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
-const defaultPort = "8080"
-
 // --- Define your frontend origin ---
 const frontendOrigin = "http://localhost:5173" // Adjust if your frontend runs on a different port
 
 // --- Authentication Middleware ---
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Println("AuthMiddleware: Entered")
-		authHeader := r.Header.Get("Authorization")
+
+// AuthMiddleware puts the user ID and roles of a valid bearer token into the request context. Roles are
+// the union of the token's role claims and the account's rows in account_roles.
+// Requests without a valid token continue anonymously.
+func AuthMiddleware(verifier *auth.Verifier, roleRepo store.RoleRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				log.Println("AuthMiddleware: No Authorization header found")
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(authenticate(r.Context(), verifier, roleRepo, authHeader)))
+		})
+	}
+}
+
+// WebsocketInit authenticates a graphql-ws connection from the "Authorization" (or "authToken") entry of
+// its connection_init payload, since browsers cannot set headers on websocket upgrades. Like
+// AuthMiddleware, connections without a valid token continue anonymously and @auth rejects them per field.
+func WebsocketInit(verifier *auth.Verifier, roleRepo store.RoleRepository) transport.WebsocketInitFunc {
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		authHeader := initPayload.Authorization()
 		if authHeader == "" {
-			log.Println("AuthMiddleware: No Authorization header found")
-			next.ServeHTTP(w, r)
-			return
+			authHeader = initPayload.GetString("authToken")
 		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			log.Println("AuthMiddleware: Malformed Authorization header")
-			next.ServeHTTP(w, r)
-			return
+		if authHeader == "" {
+			log.Println("WebsocketInit: No token in connection_init payload")
+			return ctx, &initPayload, nil
 		}
+		if !strings.Contains(authHeader, " ") {
+			authHeader = "Bearer " + authHeader // authToken carries the bare token
+		}
+		return authenticate(ctx, verifier, roleRepo, authHeader), &initPayload, nil
+	}
+}
 
-		tokenString := parts[1]
-		jwtSecret := os.Getenv("SUPABASE_JWT_SECRET")
-
-		// !!! TEMPORARY DEBUG LOG - REMOVE AFTER TESTING !!!
-		log.Printf("AuthMiddleware: Using JWT Secret from env: [%s]", jwtSecret)
-		// !!! END TEMPORARY DEBUG LOG !!!
+// authenticate returns ctx carrying the user ID and roles of the bearer token in authHeader, or ctx
+// unchanged when the header is malformed or the token invalid.
+func authenticate(ctx context.Context, verifier *auth.Verifier, roleRepo store.RoleRepository, authHeader string) context.Context {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		log.Println("AuthMiddleware: Malformed Authorization header")
+		return ctx
+	}
 
-		if jwtSecret == "" {
-			log.Println("AuthMiddleware: Error - SUPABASE_JWT_SECRET environment variable not set")
-			http.Error(w, "Internal server configuration error", http.StatusInternalServerError)
-			return
-		}
+	claims, err := verifier.Verify(ctx, parts[1])
+	if err != nil {
+		log.Printf("AuthMiddleware: Token parsing/validation error: %v", err)
+		return ctx
+	}
 
-		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
+	userID, userIDOk := claims["sub"].(string)
+	if !userIDOk || userID == "" {
+		log.Println("AuthMiddleware: Invalid or missing 'sub' (user ID) claim in token")
+		return ctx
+	}
 
-		if err != nil {
-			log.Printf("AuthMiddleware: Token parsing/validation error: %v", err)
-			next.ServeHTTP(w, r)
-			return
-		}
+	roles := rolesFromClaims(claims)
+	if granted, err := roleRepo.ListForAccount(ctx, userID); err != nil {
+		log.Printf("AuthMiddleware: Failed to load roles for %s, using token claims only: %v", userID, err)
+	} else {
+		roles = append(roles, granted...)
+	}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			log.Println("AuthMiddleware: Token claims are valid map claims and token is valid.")
-			userID, userIDOk := claims["sub"].(string)
-			log.Printf("AuthMiddleware: Parsed claims, sub raw value: %v, extracted as string ok: %v", claims["sub"], userIDOk)
+	log.Printf("AuthMiddleware: Extracted UserID: [%s]. Adding to context with key [%s].", userID, graph.AuthUserIDKey)
+	ctxWithUser := context.WithValue(ctx, graph.AuthUserIDKey, userID)
+	return context.WithValue(ctxWithUser, graph.AuthRolesKey, roles)
+}
 
-			if !userIDOk || userID == "" {
-				log.Println("AuthMiddleware: Invalid or missing 'sub' (user ID) claim in token")
-				next.ServeHTTP(w, r)
-				return
+// rolesFromClaims collects roles from a top-level "roles" claim and from Supabase's "app_metadata.roles".
+func rolesFromClaims(claims jwt.MapClaims) []string {
+	var roles []string
+	collect := func(v interface{}) {
+		list, _ := v.([]interface{})
+		for _, item := range list {
+			if role, ok := item.(string); ok && role != "" {
+				roles = append(roles, role)
 			}
-
-			log.Printf("AuthMiddleware: Extracted UserID: [%s]. Adding to context with key [%s].", userID, graph.AuthUserIDKey)
-			ctxWithUser := context.WithValue(r.Context(), graph.AuthUserIDKey, userID)
-			next.ServeHTTP(w, r.WithContext(ctxWithUser))
-		} else {
-			log.Printf("AuthMiddleware: Token claims NOT ok OR token is NOT valid (ok: %v, valid: %v)", ok, token.Valid)
-			next.ServeHTTP(w, r)
 		}
-	})
+	}
+	collect(claims["roles"])
+	if appMetadata, ok := claims["app_metadata"].(map[string]interface{}); ok {
+		collect(appMetadata["roles"])
+	}
+	return roles
 }
 
 func main() {
@@ -98,20 +127,61 @@ func main() {
 		log.Println("Warning: Error loading .env file", err)
 	}
 
-	// Ensure JWT secret is loaded
-	if os.Getenv("SUPABASE_JWT_SECRET") == "" {
-		log.Fatal("FATAL: SUPABASE_JWT_SECRET environment variable not set. Server cannot validate authentication.")
+	cfg := config.Load()
+	port := cfg.Port
+
+	// --- Token issuance and verification ---
+	var issuers []auth.IssuerConfig
+	tokens, err := auth.NewTokenIssuer(cfg.Auth.TokenSecret, cfg.Auth.TokenIssuer, cfg.Auth.TokenAudience, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+	if err != nil {
+		log.Println("Warning: AUTH_TOKEN_SECRET not set, login/refreshToken are disabled")
+	} else {
+		issuers = append(issuers, tokens.IssuerConfig())
+	}
+	if cfg.Auth.SupabaseJWTSecret != "" {
+		issuers = append(issuers, auth.IssuerConfig{
+			Issuer:    cfg.Auth.SupabaseJWTIssuer,
+			Audiences: []string{cfg.Auth.SupabaseJWTAudience},
+			Secret:    []byte(cfg.Auth.SupabaseJWTSecret),
+		})
+	}
+	for _, ti := range cfg.Auth.TrustedIssuers {
+		issuers = append(issuers, auth.IssuerConfig{Issuer: ti.Issuer, Audiences: ti.Audiences, JWKS: ti.JWKS})
 	}
-	// Optional: Log another env var to check .env loading
-	log.Printf("DEBUG: PORT from env: [%s]", os.Getenv("PORT"))
+	verifier, err := auth.NewVerifier(issuers, cfg.Auth.JWKSMaxAge, cfg.Auth.ClockSkew)
+	if err != nil {
+		log.Fatalf("FATAL: %v. Set AUTH_TOKEN_SECRET, SUPABASE_JWT_SECRET or AUTH_TRUSTED_ISSUERS.", err)
+	}
+	log.Printf("Accepting access tokens from %d issuer(s)", len(issuers))
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort
+	// --- Shared database pool, reused by every resolver ---
+	db, err := postgres.Open(cfg.DB)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	defer db.Close()
+	log.Printf("Database pool ready (max open: %d, max idle: %d, idle timeout: %s, statement timeout: %s)", cfg.DB.MaxOpenConns, cfg.DB.MaxIdleConns, cfg.DB.ConnMaxIdleTime, cfg.DB.StatementTimeout)
+
+	repos := postgres.New(db, cfg.DB)
+	resolver := &graph.Resolver{
+		Repositories:    repos,
+		Passwords:       auth.NewPasswordHasher(cfg.Auth.PasswordCost),
+		Tokens:          tokens,
+		Feed:            cfg.Feed,
+		Inbox:           cfg.Notifications,
+		Queue:           mq.NewPublisher(cfg.RabbitMQURL),
+		SuggestionCache: cache.NewTTL[string, []store.Suggestion](cfg.Suggestions.CacheTTL),
 	}
 
 	// --- Configure GraphQL server --- (rest is same as before)
-	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graph.Resolver{}}))
+	srv := handler.New(graph.NewExecutableSchema(graph.NewConfig(resolver)))
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return r.Header.Get("Origin") == frontendOrigin },
+		},
+		InitFunc: WebsocketInit(verifier, repos.Roles),
+	})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
@@ -120,6 +190,7 @@ func main() {
 	srv.Use(extension.AutomaticPersistedQuery{
 		Cache: lru.New[string](100),
 	})
+	srv.Use(loaders.Extension{Repos: repos})
 
 	// --- CORS Configuration --- (same as before)
 	c := cors.New(cors.Options{
@@ -131,13 +202,14 @@ func main() {
 
 	// --- Setup Routes and Middleware --- (same as before)
 	mux := http.NewServeMux()
-	queryHandler := c.Handler(AuthMiddleware(srv))
+	queryHandler := c.Handler(AuthMiddleware(verifier, repos.Roles)(srv))
 	mux.Handle("/query", queryHandler)
 	mux.Handle("/", playground.Handler("GraphQL playground", "/query"))
 
 	// --- Start server --- (same as before)
 	log.Printf("GraphQL playground available at http://localhost:%s/", port)
 	log.Printf("Accepting GraphQL requests at http://localhost:%s/query", port)
+	log.Printf("Accepting GraphQL subscriptions at ws://localhost:%s/query", port)
 	log.Printf("Allowing CORS requests from: %s", frontendOrigin)
 	log.Printf("Server starting on port %s...", port)
 	log.Fatal(http.ListenAndServe(":"+port, mux))
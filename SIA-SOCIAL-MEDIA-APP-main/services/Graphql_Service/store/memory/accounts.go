@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type accountRow struct {
+	account      model.Account
+	passwordHash string
+	createdAt    time.Time
+}
+
+type accountRepo struct{ *state }
+
+func (r *accountRepo) Create(_ context.Context, input store.NewAccount) (*model.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, row := range r.accounts {
+		if row.account.Email != nil && *row.account.Email == input.Email {
+			return nil, store.ErrConflict
+		}
+	}
+	now := time.Now()
+	row := &accountRow{
+		account: model.Account{
+			AccountID: uuid.NewString(),
+			Email:     &input.Email,
+			FirstName: input.FirstName,
+			LastName:  input.LastName,
+			Address:   cloneString(input.Address),
+			Phone:     cloneString(input.Phone),
+			Age:       &input.Age,
+			Gender:    cloneString(input.Gender),
+			CreatedAt: formatTime(now),
+		},
+		passwordHash: input.PasswordHash,
+		createdAt:    now,
+	}
+	r.accounts[row.account.AccountID] = row
+	return row.clone(), nil
+}
+
+func (r *accountRepo) GetByIDs(_ context.Context, accountIDs []string) (map[string]*model.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	accounts := make(map[string]*model.Account, len(accountIDs))
+	for _, id := range accountIDs {
+		if row, ok := r.accounts[id]; ok {
+			accounts[id] = row.clone()
+		}
+	}
+	return accounts, nil
+}
+
+func (r *accountRepo) GetByID(_ context.Context, accountID string) (*model.Account, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	row, ok := r.accounts[accountID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return row.clone(), nil
+}
+
+func (r *accountRepo) GetCredentials(_ context.Context, email string) (string, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for id, row := range r.accounts {
+		if row.account.Email != nil && *row.account.Email == email {
+			return id, row.passwordHash, nil
+		}
+	}
+	return "", "", store.ErrNotFound
+}
+
+func (r *accountRepo) UpdatePasswordHash(_ context.Context, accountID, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.accounts[accountID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	row.passwordHash = passwordHash
+	updatedAt := formatTime(time.Now())
+	row.account.UpdatedAt = &updatedAt
+	return nil
+}
+
+func (r *accountRepo) SetPrivate(_ context.Context, accountID string, private bool) (*model.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.accounts[accountID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	row.account.IsPrivate = private
+	updatedAt := formatTime(time.Now())
+	row.account.UpdatedAt = &updatedAt
+	return row.clone(), nil
+}
+
+func (r *accountRepo) List(_ context.Context, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rows := make([]*accountRow, 0, len(r.accounts))
+	for _, row := range r.accounts {
+		if after == nil || keysetBefore(row.createdAt, row.account.AccountID, *after) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return keysetBefore(rows[j].createdAt, rows[j].account.AccountID, store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].account.AccountID})
+	})
+	edges := []*model.AccountEdge{}
+	for i := 0; i < len(rows) && len(edges) < limit; i++ {
+		cursor := store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].account.AccountID}
+		edges = append(edges, &model.AccountEdge{Cursor: cursor.Encode(), Node: rows[i].clone()})
+	}
+	return edges, nil
+}
+
+func (row *accountRow) clone() *model.Account {
+	acc := row.account
+	acc.Email = cloneString(acc.Email)
+	acc.Address = cloneString(acc.Address)
+	acc.Phone = cloneString(acc.Phone)
+	acc.Gender = cloneString(acc.Gender)
+	if acc.Age != nil {
+		age := *acc.Age
+		acc.Age = &age
+	}
+	acc.UpdatedAt = cloneString(acc.UpdatedAt)
+	acc.IsFollowing = nil
+	return &acc
+}
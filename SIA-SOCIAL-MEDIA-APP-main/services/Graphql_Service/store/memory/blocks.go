@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"time"
+)
+
+type blockRepo struct{ *state }
+
+func (r *blockRepo) Block(_ context.Context, blockerID, blockedID string) (bool, error) {
+	return r.addEdge(r.blocks, followKey{blockerID, blockedID}), nil
+}
+
+func (r *blockRepo) Unblock(_ context.Context, blockerID, blockedID string) (bool, error) {
+	return r.removeEdge(r.blocks, followKey{blockerID, blockedID}), nil
+}
+
+func (r *blockRepo) IsBlocking(_ context.Context, blockerID, blockedID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.blocks[followKey{blockerID, blockedID}]
+	return ok, nil
+}
+
+func (r *blockRepo) ListBlocked(_ context.Context, blockerID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listAccounts(r.blocks, after, limit, func(key followKey) (string, bool) {
+		return key.followed, key.follower == blockerID
+	})
+}
+
+func (r *blockRepo) Mute(_ context.Context, muterID, mutedID string) (bool, error) {
+	return r.addEdge(r.mutes, followKey{muterID, mutedID}), nil
+}
+
+func (r *blockRepo) Unmute(_ context.Context, muterID, mutedID string) (bool, error) {
+	return r.removeEdge(r.mutes, followKey{muterID, mutedID}), nil
+}
+
+func (r *blockRepo) ListMuted(_ context.Context, muterID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listAccounts(r.mutes, after, limit, func(key followKey) (string, bool) {
+		return key.followed, key.follower == muterID
+	})
+}
+
+// addEdge stores key in edges unless present and reports whether it did.
+func (r *blockRepo) addEdge(edges map[followKey]time.Time, key followKey) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := edges[key]; exists {
+		return false
+	}
+	edges[key] = time.Now()
+	return true
+}
+
+// removeEdge deletes key from edges and reports whether it was present.
+func (r *blockRepo) removeEdge(edges map[followKey]time.Time, key followKey) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := edges[key]; !exists {
+		return false
+	}
+	delete(edges, key)
+	return true
+}
+
+// blocked reports whether a and b block each other in either direction. Callers must hold mu.
+func (s *state) blocked(a, b string) bool {
+	_, ab := s.blocks[followKey{a, b}]
+	_, ba := s.blocks[followKey{b, a}]
+	return ab || ba
+}
+
+// muted reports whether muterID muted mutedID. Callers must hold mu.
+func (s *state) muted(muterID, mutedID string) bool {
+	_, ok := s.mutes[followKey{muterID, mutedID}]
+	return ok
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type commentRow struct {
+	comment   model.Comment
+	createdAt time.Time
+	deleted   bool
+}
+
+type commentRepo struct{ *state }
+
+// toModel copies the comment. Callers must hold mu.
+func (r *commentRepo) toModel(row *commentRow) *model.Comment {
+	c := row.comment
+	c.ParentCommentID = cloneString(c.ParentCommentID)
+	c.UpdatedAt = cloneString(c.UpdatedAt)
+	c.DeletedAt = cloneString(c.DeletedAt)
+	if row.deleted {
+		c.Content = ""
+	}
+	return &c
+}
+
+func (r *commentRepo) Create(_ context.Context, input store.NewComment) (*model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if post, ok := r.posts[input.PostID]; !ok || post.deleted {
+		return nil, store.ErrNotFound
+	}
+	var parentID *string
+	if input.ParentID != "" {
+		parent, ok := r.comments[input.ParentID]
+		if !ok || parent.deleted || parent.comment.PostID != input.PostID {
+			return nil, store.ErrNotFound
+		}
+		parentID = &input.ParentID
+	}
+	now := time.Now()
+	row := &commentRow{
+		comment: model.Comment{
+			CommentID:       uuid.NewString(),
+			PostID:          input.PostID,
+			ParentCommentID: parentID,
+			AuthorID:        input.AuthorID,
+			Content:         input.Content,
+			CreatedAt:       formatTime(now),
+		},
+		createdAt: now,
+	}
+	r.comments[row.comment.CommentID] = row
+	return r.toModel(row), nil
+}
+
+func (r *commentRepo) GetByID(_ context.Context, commentID string) (*model.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	row, ok := r.comments[commentID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return r.toModel(row), nil
+}
+
+func (r *commentRepo) Update(_ context.Context, commentID, authorID, content string) (*model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.comments[commentID]
+	if !ok || row.deleted || row.comment.AuthorID != authorID {
+		return nil, store.ErrNotFound
+	}
+	updatedAt := formatTime(time.Now())
+	row.comment.Content = content
+	row.comment.UpdatedAt = &updatedAt
+	return r.toModel(row), nil
+}
+
+func (r *commentRepo) Delete(_ context.Context, commentID string) (*model.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.comments[commentID]
+	if !ok || row.deleted {
+		return nil, store.ErrNotFound
+	}
+	deletedAt := formatTime(time.Now())
+	row.deleted = true
+	row.comment.DeletedAt = &deletedAt
+	return r.toModel(row), nil
+}
+
+func (r *commentRepo) List(_ context.Context, postID, parentID string, after *store.Cursor, limit int) ([]*model.CommentEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rows := []*commentRow{}
+	for _, row := range r.comments {
+		if row.comment.PostID != postID {
+			continue
+		}
+		if rowParent := row.comment.ParentCommentID; (rowParent == nil) != (parentID == "") || (rowParent != nil && *rowParent != parentID) {
+			continue
+		}
+		if after != nil && !keysetAfter(row.createdAt, row.comment.CommentID, *after) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return keysetAfter(rows[j].createdAt, rows[j].comment.CommentID, store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].comment.CommentID})
+	})
+	edges := []*model.CommentEdge{}
+	for i := 0; i < len(rows) && len(edges) < limit; i++ {
+		cursor := store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].comment.CommentID}
+		edges = append(edges, &model.CommentEdge{Cursor: cursor.Encode(), Node: r.toModel(rows[i])})
+	}
+	return edges, nil
+}
+
+func (r *commentRepo) CountForPost(_ context.Context, postID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n := 0
+	for _, row := range r.comments {
+		if row.comment.PostID == postID && !row.deleted {
+			n++
+		}
+	}
+	return n, nil
+}
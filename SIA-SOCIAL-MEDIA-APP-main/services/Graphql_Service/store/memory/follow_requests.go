@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"sort"
+	"time"
+)
+
+type followRequestRepo struct{ *state }
+
+func (r *followRequestRepo) Create(_ context.Context, requesterID, targetID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := followKey{requesterID, targetID}
+	if _, exists := r.followRequests[key]; exists {
+		return false, nil
+	}
+	r.followRequests[key] = time.Now()
+	return true, nil
+}
+
+func (r *followRequestRepo) Delete(_ context.Context, requesterID, targetID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := followKey{requesterID, targetID}
+	if _, exists := r.followRequests[key]; !exists {
+		return false, nil
+	}
+	delete(r.followRequests, key)
+	return true, nil
+}
+
+func (r *followRequestRepo) Approve(_ context.Context, requesterID, targetID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.approve(followKey{requesterID, targetID}), nil
+}
+
+func (r *followRequestRepo) ApproveAll(_ context.Context, targetID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := []string{}
+	for key := range r.followRequests {
+		if key.followed == targetID && r.approve(key) {
+			ids = append(ids, key.follower)
+		}
+	}
+	return ids, nil
+}
+
+// approve turns the request key into a follow and reports whether that started one; a requester already
+// following only loses the request. Callers must hold mu for writing.
+func (r *followRequestRepo) approve(key followKey) bool {
+	if _, exists := r.followRequests[key]; !exists {
+		return false
+	}
+	delete(r.followRequests, key)
+	if _, following := r.follows[key]; following {
+		return false
+	}
+	r.follows[key] = time.Now()
+	return true
+}
+
+func (r *followRequestRepo) ListPending(_ context.Context, targetID string, after *store.Cursor, limit int) ([]*model.FollowRequestEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cursors := []store.Cursor{}
+	for key, requestedAt := range r.followRequests {
+		if key.followed != targetID || r.accounts[key.follower] == nil {
+			continue
+		}
+		if after == nil || keysetBefore(requestedAt, key.follower, *after) {
+			cursors = append(cursors, store.Cursor{CreatedAt: requestedAt, ID: key.follower})
+		}
+	}
+	sort.Slice(cursors, func(i, j int) bool {
+		return keysetBefore(cursors[j].CreatedAt, cursors[j].ID, cursors[i])
+	})
+	edges := []*model.FollowRequestEdge{}
+	for i := 0; i < len(cursors) && len(edges) < limit; i++ {
+		edges = append(edges, &model.FollowRequestEdge{
+			Cursor: cursors[i].Encode(),
+			Node:   &model.FollowRequest{Requester: r.accounts[cursors[i].ID].clone(), CreatedAt: formatTime(cursors[i].CreatedAt)},
+		})
+	}
+	return edges, nil
+}
+
+func (r *followRequestRepo) RequestedAmong(_ context.Context, requesterID string, targetIDs []string) (map[string]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	requested := map[string]bool{}
+	for _, id := range targetIDs {
+		if _, ok := r.followRequests[followKey{requesterID, id}]; ok {
+			requested[id] = true
+		}
+	}
+	return requested, nil
+}
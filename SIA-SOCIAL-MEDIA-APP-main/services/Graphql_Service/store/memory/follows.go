@@ -0,0 +1,200 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"sort"
+	"time"
+)
+
+// followKey identifies a follow edge: follower -> followed.
+type followKey struct {
+	follower string
+	followed string
+}
+
+type followRepo struct{ *state }
+
+func (r *followRepo) Follow(_ context.Context, followerID, followedID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := followKey{followerID, followedID}
+	if _, exists := r.follows[key]; exists {
+		return false, nil
+	}
+	r.follows[key] = time.Now()
+	return true, nil
+}
+
+func (r *followRepo) Unfollow(_ context.Context, followerID, followedID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := followKey{followerID, followedID}
+	if _, exists := r.follows[key]; !exists {
+		return false, nil
+	}
+	delete(r.follows, key)
+	return true, nil
+}
+
+func (r *followRepo) IsFollowing(_ context.Context, followerID, followedID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.follows[followKey{followerID, followedID}]
+	return exists, nil
+}
+
+func (r *followRepo) FollowedAmong(_ context.Context, followerID string, accountIDs []string) (map[string]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	followed := map[string]bool{}
+	for _, id := range accountIDs {
+		if _, ok := r.follows[followKey{followerID, id}]; ok {
+			followed[id] = true
+		}
+	}
+	return followed, nil
+}
+
+func (r *followRepo) FollowersAmong(_ context.Context, followedID string, accountIDs []string) (map[string]bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	followers := map[string]bool{}
+	for _, id := range accountIDs {
+		if _, ok := r.follows[followKey{id, followedID}]; ok {
+			followers[id] = true
+		}
+	}
+	return followers, nil
+}
+
+func (r *followRepo) FollowerIDs(_ context.Context, accountID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := []string{}
+	for key := range r.follows {
+		if key.followed == accountID {
+			ids = append(ids, key.follower)
+		}
+	}
+	return ids, nil
+}
+
+func (r *followRepo) FollowingIDs(_ context.Context, accountID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := []string{}
+	for key := range r.follows {
+		if key.follower == accountID {
+			ids = append(ids, key.followed)
+		}
+	}
+	return ids, nil
+}
+
+func (r *followRepo) Followers(_ context.Context, accountID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listAccounts(r.follows, after, limit, func(key followKey) (string, bool) {
+		return key.follower, key.followed == accountID
+	})
+}
+
+func (r *followRepo) Following(_ context.Context, accountID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listAccounts(r.follows, after, limit, func(key followKey) (string, bool) {
+		return key.followed, key.follower == accountID
+	})
+}
+
+// listAccounts pages through the accounts that other picks from edges (follows, blocks or mutes), newest
+// edge first.
+func (s *state) listAccounts(edges map[followKey]time.Time, after *store.Cursor, limit int, other func(followKey) (string, bool)) ([]*model.AccountEdge, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cursors := []store.Cursor{}
+	for key, followedAt := range edges {
+		id, ok := other(key)
+		if !ok || s.accounts[id] == nil {
+			continue
+		}
+		if after == nil || keysetBefore(followedAt, id, *after) {
+			cursors = append(cursors, store.Cursor{CreatedAt: followedAt, ID: id})
+		}
+	}
+	sort.Slice(cursors, func(i, j int) bool {
+		return keysetBefore(cursors[j].CreatedAt, cursors[j].ID, cursors[i])
+	})
+	page := []*model.AccountEdge{}
+	for i := 0; i < len(cursors) && len(page) < limit; i++ {
+		page = append(page, &model.AccountEdge{Cursor: cursors[i].Encode(), Node: s.accounts[cursors[i].ID].clone()})
+	}
+	return page, nil
+}
+
+func (r *followRepo) Counts(_ context.Context, accountIDs []string) (map[string]store.FollowCounts, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counts := make(map[string]store.FollowCounts, len(accountIDs))
+	for _, id := range accountIDs {
+		if _, ok := r.accounts[id]; ok {
+			counts[id] = store.FollowCounts{}
+		}
+	}
+	for key := range r.follows {
+		if c, ok := counts[key.followed]; ok {
+			c.Followers++
+			counts[key.followed] = c
+		}
+		if c, ok := counts[key.follower]; ok {
+			c.Following++
+			counts[key.follower] = c
+		}
+	}
+	return counts, nil
+}
+
+func (r *followRepo) Suggest(_ context.Context, viewerID string, limit int) ([]store.Suggestion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	type mutual struct {
+		id         string
+		followedAt time.Time
+	}
+	mutuals := map[string][]mutual{} // candidate -> followed accounts following it
+	for key, followedAt := range r.follows {
+		if _, ok := r.follows[followKey{viewerID, key.follower}]; !ok {
+			continue
+		}
+		candidate := key.followed
+		if candidate == viewerID || r.accounts[candidate] == nil {
+			continue
+		}
+		_, following := r.follows[followKey{viewerID, candidate}]
+		_, requested := r.followRequests[followKey{viewerID, candidate}]
+		if following || requested || r.blocked(viewerID, candidate) || r.muted(viewerID, candidate) {
+			continue
+		}
+		mutuals[candidate] = append(mutuals[candidate], mutual{key.follower, followedAt})
+	}
+
+	suggestions := make([]store.Suggestion, 0, len(mutuals))
+	for candidate, ms := range mutuals {
+		sort.Slice(ms, func(i, j int) bool {
+			return keysetBefore(ms[j].followedAt, ms[j].id, store.Cursor{CreatedAt: ms[i].followedAt, ID: ms[i].id})
+		})
+		s := store.Suggestion{AccountID: candidate, MutualCount: len(ms)}
+		for _, m := range ms[:min(len(ms), store.SuggestionMutuals)] {
+			s.MutualIDs = append(s.MutualIDs, m.id)
+		}
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].MutualCount != suggestions[j].MutualCount {
+			return suggestions[i].MutualCount > suggestions[j].MutualCount
+		}
+		return suggestions[i].AccountID < suggestions[j].AccountID
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
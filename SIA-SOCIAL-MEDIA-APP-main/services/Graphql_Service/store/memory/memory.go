@@ -0,0 +1,109 @@
+// Package memory implements the store repositories in process memory.
+//
+// It is intended for resolver tests: wire memory.New() into graph.Resolver and drive the schema through
+// the gqlgen client package without a database. All repositories returned by one New call share state.
+package memory
+
+import (
+	"graphql/graph/model"
+	"graphql/pubsub"
+	"graphql/store"
+	"sync"
+	"time"
+)
+
+// New returns empty in-memory repositories that share a single dataset.
+func New() store.Repositories {
+	s := &state{
+		accounts:       map[string]*accountRow{},
+		posts:          map[string]*postRow{},
+		follows:        map[followKey]time.Time{},
+		followRequests: map[followKey]time.Time{},
+		blocks:         map[followKey]time.Time{},
+		mutes:          map[followKey]time.Time{},
+		timelines:      map[string]map[string]bool{},
+		notifications:  []*notificationRow{},
+		profiles:       map[string]*profileRow{},
+		refreshTokens:  map[string]*refreshTokenRow{},
+		roles:          map[string]map[string]bool{},
+		comments:       map[string]*commentRow{},
+		reactions:      map[reactionKey]string{},
+		preferences:    map[string]*preferenceRow{},
+		conversations:  map[string]*conversationRow{},
+		messages:       map[string]*messageRow{},
+
+		notificationHub: pubsub.NewHub[*model.Notification](),
+		unreadCountHub:  pubsub.NewHub[int](),
+		messageHub:      pubsub.NewHub[*model.Message](),
+	}
+	return store.Repositories{
+		Accounts:                &accountRepo{s},
+		Posts:                   &postRepo{s},
+		Follows:                 &followRepo{s},
+		FollowRequests:          &followRequestRepo{s},
+		Blocks:                  &blockRepo{s},
+		Timelines:               &timelineRepo{s},
+		Notifications:           &notificationRepo{s},
+		NotificationPreferences: &notificationPreferenceRepo{s},
+		Profiles:                &profileRepo{s},
+		RefreshTokens:           &refreshTokenRepo{s},
+		Roles:                   &roleRepo{s},
+		Comments:                &commentRepo{s},
+		Reactions:               &reactionRepo{s},
+		Messages:                &messageRepo{s},
+	}
+}
+
+// state is the dataset shared by every repository. All access goes through mu.
+type state struct {
+	mu sync.RWMutex
+
+	accounts       map[string]*accountRow
+	posts          map[string]*postRow
+	follows        map[followKey]time.Time
+	followRequests map[followKey]time.Time    // requester -> target, requested at
+	blocks         map[followKey]time.Time    // blocker -> blocked, blocked at
+	mutes          map[followKey]time.Time    // muter -> muted, muted at
+	timelines      map[string]map[string]bool // owner ID -> post IDs
+	notifications  []*notificationRow
+	profiles       map[string]*profileRow
+	refreshTokens  map[string]*refreshTokenRow // keyed by digest
+	roles          map[string]map[string]bool  // account ID -> role -> granted
+	comments       map[string]*commentRow
+	reactions      map[reactionKey]string    // -> reaction
+	preferences    map[string]*preferenceRow // account ID -> notification settings
+	conversations  map[string]*conversationRow
+	messages       map[string]*messageRow
+
+	// The hubs are safe for concurrent use without mu.
+	notificationHub *pubsub.Hub[*model.Notification]
+	unreadCountHub  *pubsub.Hub[int]
+	messageHub      *pubsub.Hub[*model.Message]
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// keysetAfter reports whether (t, id) sorts after c in (created_at, id) order, matching the Postgres
+// row comparison used for cursor pagination.
+func keysetAfter(t time.Time, id string, c store.Cursor) bool {
+	if !t.Equal(c.CreatedAt) {
+		return t.After(c.CreatedAt)
+	}
+	return id > c.ID
+}
+
+// keysetBefore reports whether (t, id) sorts before c in (created_at, id) order, i.e. comes after c in a
+// newest-first listing.
+func keysetBefore(t time.Time, id string, c store.Cursor) bool {
+	return keysetAfter(c.CreatedAt, c.ID, store.Cursor{CreatedAt: t, ID: id})
+}
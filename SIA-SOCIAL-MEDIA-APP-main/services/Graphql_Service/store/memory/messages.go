@@ -0,0 +1,361 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type conversationRow struct {
+	id            string
+	isGroup       bool
+	title         *string
+	directKey     string // "smallerID:largerID" for direct conversations
+	createdAt     time.Time
+	lastMessageAt time.Time
+	members       []*memberRow
+}
+
+type memberRow struct {
+	accountID  string
+	lastReadID string
+	lastReadAt time.Time
+}
+
+type messageRow struct {
+	id             string
+	conversationID string
+	senderID       string
+	body           *string
+	createdAt      time.Time
+	editedAt       *time.Time
+	deletedAt      *time.Time
+}
+
+func (c *conversationRow) toModel() *model.Conversation {
+	return &model.Conversation{
+		ConversationID: c.id,
+		IsGroup:        c.isGroup,
+		Title:          cloneString(c.title),
+		CreatedAt:      formatTime(c.createdAt),
+		LastMessageAt:  formatTime(c.lastMessageAt),
+	}
+}
+
+func (c *conversationRow) member(accountID string) *memberRow {
+	for _, m := range c.members {
+		if m.accountID == accountID {
+			return m
+		}
+	}
+	return nil
+}
+
+func (m *messageRow) toModel() *model.Message {
+	msg := &model.Message{
+		MessageID:      m.id,
+		ConversationID: m.conversationID,
+		SenderID:       m.senderID,
+		Body:           cloneString(m.body),
+		CreatedAt:      formatTime(m.createdAt),
+	}
+	if m.editedAt != nil {
+		s := formatTime(*m.editedAt)
+		msg.EditedAt = &s
+	}
+	if m.deletedAt != nil {
+		s := formatTime(*m.deletedAt)
+		msg.DeletedAt = &s
+	}
+	return msg
+}
+
+type messageRepo struct{ *state }
+
+func (r *messageRepo) CreateConversation(_ context.Context, c store.NewConversation) (*model.Conversation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var directKey string
+	if !c.IsGroup {
+		ids := slices.Sorted(slices.Values(c.MemberIDs))
+		directKey = ids[0] + ":" + ids[1]
+		for _, conv := range r.conversations {
+			if conv.directKey == directKey {
+				return conv.toModel(), nil
+			}
+		}
+	}
+
+	now := time.Now()
+	conv := &conversationRow{
+		id:            uuid.NewString(),
+		isGroup:       c.IsGroup,
+		title:         cloneString(c.Title),
+		directKey:     directKey,
+		createdAt:     now,
+		lastMessageAt: now,
+	}
+	for _, id := range c.MemberIDs {
+		conv.members = append(conv.members, &memberRow{accountID: id})
+	}
+	r.conversations[conv.id] = conv
+	return conv.toModel(), nil
+}
+
+func (r *messageRepo) GetConversation(_ context.Context, conversationID, memberID string) (*model.Conversation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	conv, err := r.memberConversation(conversationID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	return conv.toModel(), nil
+}
+
+func (r *messageRepo) ListConversations(_ context.Context, memberID string, after *store.Cursor, limit int) ([]*model.ConversationEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var rows []*conversationRow
+	for _, conv := range r.conversations {
+		if conv.member(memberID) == nil {
+			continue
+		}
+		if after != nil && !keysetBefore(conv.lastMessageAt, conv.id, *after) {
+			continue
+		}
+		rows = append(rows, conv)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return keysetAfter(rows[i].lastMessageAt, rows[i].id, store.Cursor{CreatedAt: rows[j].lastMessageAt, ID: rows[j].id})
+	})
+
+	edges := []*model.ConversationEdge{}
+	for _, conv := range rows {
+		if len(edges) == limit {
+			break
+		}
+		cursor := store.Cursor{CreatedAt: conv.lastMessageAt, ID: conv.id}
+		edges = append(edges, &model.ConversationEdge{Cursor: cursor.Encode(), Node: conv.toModel()})
+	}
+	return edges, nil
+}
+
+func (r *messageRepo) Members(_ context.Context, conversationIDs []string) (map[string][]store.ConversationMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	members := map[string][]store.ConversationMember{}
+	for _, id := range conversationIDs {
+		conv, ok := r.conversations[id]
+		if !ok {
+			continue
+		}
+		for _, m := range conv.members {
+			members[id] = append(members[id], store.ConversationMember{
+				AccountID:         m.accountID,
+				LastReadMessageID: m.lastReadID,
+				LastReadAt:        m.lastReadAt,
+			})
+		}
+	}
+	return members, nil
+}
+
+func (r *messageRepo) LastMessages(_ context.Context, viewerID string, conversationIDs []string) (map[string]*model.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	want := map[string]bool{}
+	for _, id := range conversationIDs {
+		want[id] = true
+	}
+	last := map[string]*messageRow{}
+	for _, m := range r.messages {
+		if !want[m.conversationID] || r.blocked(m.senderID, viewerID) {
+			continue
+		}
+		if cur := last[m.conversationID]; cur == nil || keysetAfter(m.createdAt, m.id, store.Cursor{CreatedAt: cur.createdAt, ID: cur.id}) {
+			last[m.conversationID] = m
+		}
+	}
+
+	messages := map[string]*model.Message{}
+	for id, m := range last {
+		messages[id] = m.toModel()
+	}
+	return messages, nil
+}
+
+func (r *messageRepo) UnreadCounts(_ context.Context, memberID string, conversationIDs []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counts := map[string]int{}
+	readAt := map[string]time.Time{}
+	for _, id := range conversationIDs {
+		if conv, ok := r.conversations[id]; ok {
+			if m := conv.member(memberID); m != nil {
+				counts[id] = 0
+				readAt[id] = m.lastReadAt
+			}
+		}
+	}
+	for _, m := range r.messages {
+		since, ok := readAt[m.conversationID]
+		if !ok || m.senderID == memberID || m.deletedAt != nil || !m.createdAt.After(since) || r.blocked(m.senderID, memberID) {
+			continue
+		}
+		counts[m.conversationID]++
+	}
+	return counts, nil
+}
+
+func (r *messageRepo) MarkRead(_ context.Context, conversationID, memberID, messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conv, ok := r.conversations[conversationID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	var target *messageRow
+	for _, m := range r.messages {
+		if m.conversationID != conversationID || (messageID != "" && m.id != messageID) {
+			continue
+		}
+		if target == nil || keysetAfter(m.createdAt, m.id, store.Cursor{CreatedAt: target.createdAt, ID: target.id}) {
+			target = m
+		}
+	}
+	if target == nil {
+		if messageID != "" {
+			return store.ErrNotFound
+		}
+		return nil
+	}
+	// The position only moves forward, so marking an older message read is a no-op rather than an error.
+	if member := conv.member(memberID); member != nil &&
+		(member.lastReadID == "" || keysetAfter(target.createdAt, target.id, store.Cursor{CreatedAt: member.lastReadAt, ID: member.lastReadID})) {
+		member.lastReadID, member.lastReadAt = target.id, target.createdAt
+	}
+	return nil
+}
+
+func (r *messageRepo) Send(_ context.Context, conversationID, senderID, body string) (*model.Message, error) {
+	r.mu.Lock()
+	conv, err := r.memberConversation(conversationID, senderID)
+	if err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	row := &messageRow{
+		id:             uuid.NewString(),
+		conversationID: conversationID,
+		senderID:       senderID,
+		body:           &body,
+		createdAt:      time.Now(),
+	}
+	r.messages[row.id] = row
+	conv.lastMessageAt = row.createdAt
+	sender := conv.member(senderID)
+	sender.lastReadID, sender.lastReadAt = row.id, row.createdAt
+	return r.publishMessage(conv, row), nil
+}
+
+func (r *messageRepo) GetMessage(_ context.Context, messageID, memberID string) (*model.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.messages[messageID]
+	if !ok || r.conversations[m.conversationID].member(memberID) == nil {
+		return nil, store.ErrNotFound
+	}
+	return m.toModel(), nil
+}
+
+func (r *messageRepo) Edit(_ context.Context, messageID, senderID, body string) (*model.Message, error) {
+	r.mu.Lock()
+	m, ok := r.messages[messageID]
+	if !ok || m.senderID != senderID || m.deletedAt != nil {
+		r.mu.Unlock()
+		return nil, store.ErrNotFound
+	}
+	now := time.Now()
+	m.body, m.editedAt = &body, &now
+	return r.publishMessage(r.conversations[m.conversationID], m), nil
+}
+
+func (r *messageRepo) Delete(_ context.Context, messageID, senderID string) (*model.Message, error) {
+	r.mu.Lock()
+	m, ok := r.messages[messageID]
+	if !ok || m.senderID != senderID || m.deletedAt != nil {
+		r.mu.Unlock()
+		return nil, store.ErrNotFound
+	}
+	now := time.Now()
+	m.body, m.deletedAt = nil, &now
+	return r.publishMessage(r.conversations[m.conversationID], m), nil
+}
+
+func (r *messageRepo) ListMessages(_ context.Context, conversationID, viewerID string, after *store.Cursor, limit int) ([]*model.MessageEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, err := r.memberConversation(conversationID, viewerID); err != nil {
+		return []*model.MessageEdge{}, nil
+	}
+	var rows []*messageRow
+	for _, m := range r.messages {
+		if m.conversationID != conversationID || r.blocked(m.senderID, viewerID) {
+			continue
+		}
+		if after != nil && !keysetBefore(m.createdAt, m.id, *after) {
+			continue
+		}
+		rows = append(rows, m)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return keysetAfter(rows[i].createdAt, rows[i].id, store.Cursor{CreatedAt: rows[j].createdAt, ID: rows[j].id})
+	})
+
+	edges := []*model.MessageEdge{}
+	for _, m := range rows {
+		if len(edges) == limit {
+			break
+		}
+		cursor := store.Cursor{CreatedAt: m.createdAt, ID: m.id}
+		edges = append(edges, &model.MessageEdge{Cursor: cursor.Encode(), Node: m.toModel()})
+	}
+	return edges, nil
+}
+
+// Subscribe is served by an in-process hub; the memory store has no other replicas to hear from.
+func (r *messageRepo) Subscribe(ctx context.Context, memberID string) (<-chan *model.Message, error) {
+	return r.messageHub.Subscribe(ctx, memberID), nil
+}
+
+// memberConversation returns conversationID if memberID is in it. Callers must hold mu.
+func (s *state) memberConversation(conversationID, memberID string) (*conversationRow, error) {
+	conv, ok := s.conversations[conversationID]
+	if !ok || conv.member(memberID) == nil {
+		return nil, store.ErrNotFound
+	}
+	return conv, nil
+}
+
+// publishMessage releases mu, which the caller must hold for writing, and delivers m to the members of conv
+// who may see it.
+func (s *state) publishMessage(conv *conversationRow, m *messageRow) *model.Message {
+	var recipients []string
+	for _, member := range conv.members {
+		if !s.blocked(m.senderID, member.accountID) {
+			recipients = append(recipients, member.accountID)
+		}
+	}
+	msg := m.toModel()
+	s.mu.Unlock()
+
+	for _, id := range recipients {
+		s.messageHub.Publish(id, msg)
+	}
+	return msg
+}
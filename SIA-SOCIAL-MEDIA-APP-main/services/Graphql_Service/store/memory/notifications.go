@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type notificationRow struct {
+	id               string
+	recipientID      string
+	triggeringUserID string
+	notificationType model.NotificationType
+	entityID         string
+	isRead           bool
+	createdAt        time.Time
+}
+
+type notificationRepo struct{ *state }
+
+func (r *notificationRepo) Create(_ context.Context, n store.NewNotification) error {
+	r.mu.Lock()
+	createdAt := n.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	row := &notificationRow{
+		id:               uuid.NewString(),
+		recipientID:      n.RecipientID,
+		triggeringUserID: n.TriggeringUserID,
+		notificationType: n.Type,
+		entityID:         n.EntityID,
+		createdAt:        createdAt,
+	}
+	r.notifications = append(r.notifications, row)
+	notif := r.toModel(row)
+	unread := r.countUnread(n.RecipientID)
+	r.mu.Unlock()
+
+	r.notificationHub.Publish(n.RecipientID, notif)
+	r.unreadCountHub.Publish(n.RecipientID, unread)
+	return nil
+}
+
+func (r *notificationRepo) CountUnread(_ context.Context, recipientID string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.countUnread(recipientID), nil
+}
+
+func (r *notificationRepo) MarkRead(_ context.Context, recipientID string, notificationIDs []string) error {
+	ids := map[string]bool{}
+	for _, id := range notificationIDs {
+		ids[id] = true
+	}
+	r.markRead(recipientID, func(row *notificationRow) bool { return ids[row.id] })
+	return nil
+}
+
+func (r *notificationRepo) MarkAllRead(_ context.Context, recipientID string, before time.Time) error {
+	r.markRead(recipientID, func(row *notificationRow) bool { return !row.createdAt.After(before) })
+	return nil
+}
+
+// markRead marks recipientID's unread notifications matching match as read and publishes the new count
+// if any changed.
+func (r *notificationRepo) markRead(recipientID string, match func(*notificationRow) bool) {
+	r.mu.Lock()
+	changed := false
+	for _, row := range r.notifications {
+		if row.recipientID == recipientID && !row.isRead && match(row) {
+			row.isRead = true
+			changed = true
+		}
+	}
+	unread := r.countUnread(recipientID)
+	r.mu.Unlock()
+
+	if changed {
+		r.unreadCountHub.Publish(recipientID, unread)
+	}
+}
+
+// countUnread counts recipientID's unread notifications. Callers must hold mu.
+func (r *notificationRepo) countUnread(recipientID string) int {
+	n := 0
+	for _, row := range r.notifications {
+		if row.recipientID == recipientID && !row.isRead && !r.actorHidden(row) {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *notificationRepo) GetByID(_ context.Context, notificationID string) (*model.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, row := range r.notifications {
+		if row.id == notificationID {
+			return r.toModel(row), nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+// Subscribe is served by an in-process hub; the memory store has no other replicas to hear from.
+func (r *notificationRepo) Subscribe(ctx context.Context, recipientID string) (<-chan *model.Notification, error) {
+	return r.notificationHub.Subscribe(ctx, recipientID), nil
+}
+
+func (r *notificationRepo) SubscribeUnreadCount(ctx context.Context, recipientID string) (<-chan int, error) {
+	return r.unreadCountHub.Subscribe(ctx, recipientID), nil
+}
+
+func (r *notificationRepo) ListForRecipient(_ context.Context, recipientID string, filter store.NotificationFilter, after *store.Cursor, limit int) ([]*model.NotificationEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rows := []*notificationRow{}
+	for _, row := range r.notifications {
+		if row.recipientID != recipientID {
+			continue
+		}
+		if !matchesFilter(row, filter) || r.actorHidden(row) {
+			continue
+		}
+		if after != nil && !keysetBefore(row.createdAt, row.id, *after) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return keysetBefore(rows[j].createdAt, rows[j].id, store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].id})
+	})
+	edges := []*model.NotificationEdge{}
+	for i := 0; i < len(rows) && len(edges) < limit; i++ {
+		cursor := store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].id}
+		edges = append(edges, &model.NotificationEdge{Cursor: cursor.Encode(), Node: r.toModel(rows[i])})
+	}
+	return edges, nil
+}
+
+func (r *notificationRepo) ListGroups(_ context.Context, recipientID string, filter store.NotificationFilter, window time.Duration, after *store.Cursor, limit int) ([]*store.NotificationGroup, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seconds := int64(window / time.Second)
+	byKey := map[store.NotificationGroupKey]*store.NotificationGroup{}
+	actors := map[store.NotificationGroupKey]map[string]time.Time{} // actor -> latest notification
+	for _, row := range r.notifications {
+		if row.recipientID != recipientID || !matchesFilter(row, filter) || r.actorHidden(row) {
+			continue
+		}
+		start := row.createdAt.Unix() / seconds * seconds
+		key := store.NotificationGroupKey{Type: row.notificationType, EntityID: row.entityID, WindowStart: time.Unix(start, 0).UTC()}
+		if row.notificationType == model.NotificationTypeNewFollower || row.notificationType == model.NotificationTypeFollowRequest {
+			key.EntityID = ""
+		}
+		g := byKey[key]
+		if g == nil {
+			g = &store.NotificationGroup{Key: key}
+			byKey[key] = g
+			actors[key] = map[string]time.Time{}
+		}
+		g.Count++
+		if !row.isRead {
+			g.UnreadCount++
+		}
+		if row.createdAt.After(g.LatestAt) {
+			g.LatestAt = row.createdAt
+		}
+		if row.triggeringUserID != "" && row.createdAt.After(actors[key][row.triggeringUserID]) {
+			actors[key][row.triggeringUserID] = row.createdAt
+		}
+	}
+
+	groups := []*store.NotificationGroup{}
+	for key, g := range byKey {
+		if after != nil && !keysetBefore(g.LatestAt, key.String(), *after) {
+			continue
+		}
+		g.ActorCount = len(actors[key])
+		ids := make([]string, 0, len(actors[key]))
+		for id := range actors[key] {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return actors[key][ids[i]].After(actors[key][ids[j]]) })
+		g.ActorIDs = ids[:min(len(ids), store.NotificationGroupActors)]
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return keysetBefore(groups[j].LatestAt, groups[j].Key.String(), groups[i].Cursor())
+	})
+	if len(groups) > limit {
+		groups = groups[:limit]
+	}
+	return groups, nil
+}
+
+// toModel converts a row and attaches the triggering account. Callers must hold mu.
+func (r *notificationRepo) toModel(row *notificationRow) *model.Notification {
+	notif := &model.Notification{
+		NotificationID:   row.id,
+		RecipientUserID:  row.recipientID,
+		NotificationType: row.notificationType,
+		IsRead:           row.isRead,
+		CreatedAt:        formatTime(row.createdAt),
+	}
+	if row.entityID != "" {
+		entityID := row.entityID
+		notif.EntityID = &entityID
+	}
+	if acc, ok := r.accounts[row.triggeringUserID]; ok {
+		notif.TriggeringUser = acc.clone()
+	}
+	return notif
+}
+
+// actorHidden reports whether row was caused by an account its recipient blocks, is blocked by or muted.
+// Such notifications are already suppressed when created; this also hides the ones from before the block
+// or mute. Callers must hold mu.
+func (s *state) actorHidden(row *notificationRow) bool {
+	return row.triggeringUserID != "" && (s.blocked(row.recipientID, row.triggeringUserID) || s.muted(row.recipientID, row.triggeringUserID))
+}
+
+func matchesFilter(row *notificationRow, filter store.NotificationFilter) bool {
+	if filter.IsRead != nil && row.isRead != *filter.IsRead {
+		return false
+	}
+	if len(filter.Types) > 0 && !slices.Contains(filter.Types, row.notificationType) {
+		return false
+	}
+	if filter.EntityID != "" && row.entityID != filter.EntityID {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && row.createdAt.Before(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !row.createdAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	return true
+}
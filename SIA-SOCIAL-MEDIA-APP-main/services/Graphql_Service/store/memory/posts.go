@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type postRow struct {
+	post      model.Post
+	createdAt time.Time
+	writtenAt time.Time // when the current version was written
+	deleted   bool
+	revisions []*model.PostRevision // oldest first
+}
+
+type postRepo struct{ *state }
+
+func (r *postRepo) Create(_ context.Context, input store.NewPost) (*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	row := &postRow{
+		post: model.Post{
+			PostID:    uuid.NewString(),
+			Title:     input.Title,
+			Content:   input.Content,
+			AuthorID:  input.AuthorID,
+			CreatedAt: formatTime(now),
+		},
+		createdAt: now,
+		writtenAt: now,
+	}
+	r.posts[row.post.PostID] = row
+	post := row.post
+	return &post, nil
+}
+
+func (r *postRepo) GetByID(_ context.Context, postID string) (*model.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	row, ok := r.posts[postID]
+	if !ok || row.deleted {
+		return nil, store.ErrNotFound
+	}
+	return r.toModel(row), nil
+}
+
+func (r *postRepo) GetVisible(_ context.Context, postID, viewerID string) (*model.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	row, ok := r.posts[postID]
+	if !ok || row.deleted || !r.visibleTo(row.post.AuthorID, viewerID) {
+		return nil, store.ErrNotFound
+	}
+	return r.toModel(row), nil
+}
+
+func (r *postRepo) ListRecent(_ context.Context, viewerID string, after *store.Cursor, limit int) ([]*model.PostEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.list(func(row *postRow) bool {
+		return r.visibleTo(row.post.AuthorID, viewerID) && !r.muted(viewerID, row.post.AuthorID)
+	}, after, limit), nil
+}
+
+func (r *postRepo) Update(_ context.Context, postID, authorID string, update store.PostUpdate) (*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.posts[postID]
+	if !ok || row.deleted || row.post.AuthorID != authorID {
+		return nil, store.ErrNotFound
+	}
+	now := time.Now()
+	row.revisions = append(row.revisions, &model.PostRevision{
+		RevisionID: uuid.NewString(),
+		Title:      row.post.Title,
+		Content:    row.post.Content,
+		CreatedAt:  formatTime(row.writtenAt),
+		ReplacedAt: formatTime(now),
+	})
+	if update.Title != nil {
+		row.post.Title = *update.Title
+	}
+	if update.Content != nil {
+		row.post.Content = *update.Content
+	}
+	updatedAt := formatTime(now)
+	row.post.UpdatedAt = &updatedAt
+	row.writtenAt = now
+	return r.toModel(row), nil
+}
+
+func (r *postRepo) Delete(_ context.Context, postID, authorID string) (*model.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.posts[postID]
+	if !ok || row.deleted || row.post.AuthorID != authorID {
+		return nil, store.ErrNotFound
+	}
+	row.deleted = true
+	for _, postIDs := range r.timelines {
+		delete(postIDs, postID)
+	}
+	post := r.toModel(row)
+	deletedAt := formatTime(time.Now())
+	post.DeletedAt = &deletedAt
+	return post, nil
+}
+
+func (r *postRepo) ListRevisions(_ context.Context, postID string) ([]*model.PostRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	revisions := []*model.PostRevision{}
+	if row, ok := r.posts[postID]; ok {
+		for i := len(row.revisions) - 1; i >= 0; i-- {
+			rev := *row.revisions[i]
+			revisions = append(revisions, &rev)
+		}
+	}
+	return revisions, nil
+}
+
+// list returns matching posts newest first, starting after the given cursor. Callers must hold mu.
+func (r *postRepo) list(match func(*postRow) bool, after *store.Cursor, limit int) []*model.PostEdge {
+	rows := []*postRow{}
+	for _, row := range r.posts {
+		if row.deleted || !match(row) {
+			continue
+		}
+		if after != nil && !keysetBefore(row.createdAt, row.post.PostID, *after) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortNewestFirst(rows)
+	edges := []*model.PostEdge{}
+	for i := 0; i < len(rows) && len(edges) < limit; i++ {
+		cursor := store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].post.PostID}
+		edges = append(edges, &model.PostEdge{Cursor: cursor.Encode(), Node: r.toModel(rows[i])})
+	}
+	return edges
+}
+
+// sortNewestFirst orders posts by (created_at, id) descending, like the Postgres feed queries.
+func sortNewestFirst(rows []*postRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		return keysetBefore(rows[j].createdAt, rows[j].post.PostID, store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].post.PostID})
+	})
+}
+
+// toModel copies a post row. Callers must hold mu.
+func (r *postRepo) toModel(row *postRow) *model.Post {
+	post := row.post
+	post.UpdatedAt = cloneString(post.UpdatedAt)
+	return &post
+}
+
+// visibleTo reports whether authorID's posts may be shown to viewerID: neither blocks the other, and the
+// author is public, is the viewer, or is followed by the viewer. Callers must hold mu.
+func (s *state) visibleTo(authorID, viewerID string) bool {
+	if s.blocked(authorID, viewerID) {
+		return false
+	}
+	if author, ok := s.accounts[authorID]; !ok || !author.account.IsPrivate || authorID == viewerID {
+		return true
+	}
+	_, following := s.follows[followKey{viewerID, authorID}]
+	return following
+}
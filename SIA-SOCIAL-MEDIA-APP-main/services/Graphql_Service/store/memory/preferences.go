@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"sort"
+	"time"
+)
+
+// preferenceRow holds one account's notification settings.
+type preferenceRow struct {
+	types      map[model.NotificationType]store.NotificationChannels
+	webhookURL string
+	mutes      map[string]time.Time // muted author ID -> muted at
+}
+
+type notificationPreferenceRepo struct{ *state }
+
+func (r *notificationPreferenceRepo) Get(_ context.Context, accountID string) (*store.NotificationPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	prefs := &store.NotificationPreferences{Types: map[model.NotificationType]store.NotificationChannels{}}
+	if row := r.preferences[accountID]; row != nil {
+		for t, ch := range row.types {
+			prefs.Types[t] = ch
+		}
+		prefs.WebhookURL = row.webhookURL
+	}
+	return prefs, nil
+}
+
+func (r *notificationPreferenceRepo) Update(_ context.Context, accountID string, update store.NotificationPreferencesUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.preferenceRow(accountID)
+	for t, ch := range update.Types {
+		row.types[t] = ch
+	}
+	if update.WebhookURL != nil {
+		row.webhookURL = *update.WebhookURL
+	}
+	return nil
+}
+
+func (r *notificationPreferenceRepo) Deliveries(_ context.Context, t model.NotificationType, actorID string, recipientIDs []string) (map[string]store.NotificationDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	deliveries := map[string]store.NotificationDelivery{}
+	for _, id := range recipientIDs {
+		account, ok := r.accounts[id]
+		if !ok || r.blocked(id, actorID) || r.muted(id, actorID) {
+			continue
+		}
+		d := store.NotificationDelivery{NotificationChannels: store.DefaultNotificationChannels}
+		if account.account.Email != nil {
+			d.Email = *account.account.Email
+		}
+		if row := r.preferences[id]; row != nil {
+			if t == model.NotificationTypeNewPost {
+				if _, muted := row.mutes[actorID]; muted {
+					continue
+				}
+			}
+			if ch, ok := row.types[t]; ok {
+				d.NotificationChannels = ch
+			}
+			d.WebhookURL = row.webhookURL
+		}
+		d.Webhook = d.Webhook && d.WebhookURL != ""
+		if d.InApp || d.NotificationChannels.Email || d.Webhook {
+			deliveries[id] = d
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *notificationPreferenceRepo) MuteNewPosts(_ context.Context, accountID, authorID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.preferenceRow(accountID)
+	if _, exists := row.mutes[authorID]; exists {
+		return false, nil
+	}
+	row.mutes[authorID] = time.Now()
+	return true, nil
+}
+
+func (r *notificationPreferenceRepo) UnmuteNewPosts(_ context.Context, accountID, authorID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row := r.preferences[accountID]
+	if row == nil {
+		return false, nil
+	}
+	if _, exists := row.mutes[authorID]; !exists {
+		return false, nil
+	}
+	delete(row.mutes, authorID)
+	return true, nil
+}
+
+func (r *notificationPreferenceRepo) MutedNewPostAuthors(_ context.Context, accountID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := []string{}
+	row := r.preferences[accountID]
+	if row == nil {
+		return ids, nil
+	}
+	for id := range row.mutes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ti, tj := row.mutes[ids[i]], row.mutes[ids[j]]
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return ids[i] > ids[j]
+	})
+	return ids, nil
+}
+
+// preferenceRow returns accountID's settings, creating them if needed. The caller must hold mu for writing.
+func (r *notificationPreferenceRepo) preferenceRow(accountID string) *preferenceRow {
+	row := r.preferences[accountID]
+	if row == nil {
+		row = &preferenceRow{types: map[model.NotificationType]store.NotificationChannels{}, mutes: map[string]time.Time{}}
+		r.preferences[accountID] = row
+	}
+	return row
+}
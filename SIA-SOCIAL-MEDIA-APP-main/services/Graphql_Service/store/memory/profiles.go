@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+	"sort"
+)
+
+type profileRow struct {
+	profile model.Profile
+}
+
+type profileRepo struct{ *state }
+
+func (r *profileRepo) Create(_ context.Context, input store.NewProfile) (*model.Profile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.profiles[input.ProfileID]; exists {
+		return nil, store.ErrConflict
+	}
+	for _, row := range r.profiles {
+		if row.profile.Username == input.Username {
+			return nil, store.ErrUsernameTaken
+		}
+		if row.profile.Email != nil && *row.profile.Email == input.Email {
+			return nil, store.ErrConflict
+		}
+	}
+	row := &profileRow{profile: model.Profile{
+		ProfileID:         input.ProfileID,
+		Username:          input.Username,
+		Email:             &input.Email,
+		FirstName:         cloneString(input.FirstName),
+		MiddleName:        cloneString(input.MiddleName),
+		LastName:          cloneString(input.LastName),
+		Bio:               cloneString(input.Bio),
+		ProfilePictureURL: cloneString(input.ProfilePictureURL),
+		BannerPictureURL:  cloneString(input.BannerPictureURL),
+		DateOfBirth:       cloneString(input.DateOfBirth),
+		Address:           cloneString(input.Address),
+	}}
+	r.profiles[input.ProfileID] = row
+	return row.clone(), nil
+}
+
+func (r *profileRepo) Update(_ context.Context, profileID string, update store.ProfileUpdate) (*model.Profile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.profiles[profileID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	if update.Username != nil && *update.Username != row.profile.Username {
+		for _, other := range r.profiles {
+			if other.profile.Username == *update.Username {
+				return nil, store.ErrUsernameTaken
+			}
+		}
+		row.profile.Username = *update.Username
+	}
+	set := func(field **string, value *string) {
+		if value == nil {
+			return
+		}
+		if *value == "" {
+			*field = nil
+			return
+		}
+		*field = cloneString(value)
+	}
+	set(&row.profile.MiddleName, update.MiddleName)
+	set(&row.profile.Bio, update.Bio)
+	set(&row.profile.ProfilePictureURL, update.ProfilePictureURL)
+	set(&row.profile.BannerPictureURL, update.BannerPictureURL)
+	set(&row.profile.DateOfBirth, update.DateOfBirth)
+	set(&row.profile.Address, update.Address)
+	return row.clone(), nil
+}
+
+func (r *profileRepo) GetByID(_ context.Context, profileID string) (*model.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	row, ok := r.profiles[profileID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return row.clone(), nil
+}
+
+func (r *profileRepo) List(_ context.Context, viewerID string) ([]*model.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	profiles := make([]*model.Profile, 0, len(r.profiles))
+	for id, row := range r.profiles {
+		if r.blocked(id, viewerID) {
+			continue
+		}
+		profiles = append(profiles, row.clone())
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Username < profiles[j].Username })
+	return profiles, nil
+}
+
+func (row *profileRow) clone() *model.Profile {
+	p := row.profile
+	p.Email = cloneString(p.Email)
+	p.FirstName = cloneString(p.FirstName)
+	p.MiddleName = cloneString(p.MiddleName)
+	p.LastName = cloneString(p.LastName)
+	p.Bio = cloneString(p.Bio)
+	p.ProfilePictureURL = cloneString(p.ProfilePictureURL)
+	p.BannerPictureURL = cloneString(p.BannerPictureURL)
+	p.DateOfBirth = cloneString(p.DateOfBirth)
+	p.Address = cloneString(p.Address)
+	return &p
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"graphql/store"
+)
+
+type reactionKey struct {
+	target    store.ReactionTarget
+	accountID string
+}
+
+type reactionRepo struct{ *state }
+
+func (r *reactionRepo) Set(_ context.Context, target store.ReactionTarget, accountID, reaction string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := reactionKey{target, accountID}
+	_, existed := r.reactions[key]
+	r.reactions[key] = reaction
+	return !existed, nil
+}
+
+func (r *reactionRepo) Remove(_ context.Context, target store.ReactionTarget, accountID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := reactionKey{target, accountID}
+	if _, ok := r.reactions[key]; !ok {
+		return false, nil
+	}
+	delete(r.reactions, key)
+	return true, nil
+}
+
+func (r *reactionRepo) Counts(_ context.Context, kind string, ids []string) (map[string]map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	counts := map[string]map[string]int{}
+	for key, reaction := range r.reactions {
+		if key.target.Kind != kind || !wanted[key.target.ID] {
+			continue
+		}
+		if counts[key.target.ID] == nil {
+			counts[key.target.ID] = map[string]int{}
+		}
+		counts[key.target.ID][reaction]++
+	}
+	return counts, nil
+}
+
+func (r *reactionRepo) ForAccount(_ context.Context, kind string, ids []string, accountID string) (map[string]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reactions := map[string]string{}
+	for _, id := range ids {
+		if reaction, ok := r.reactions[reactionKey{store.ReactionTarget{Kind: kind, ID: id}, accountID}]; ok {
+			reactions[id] = reaction
+		}
+	}
+	return reactions, nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"graphql/store"
+	"time"
+)
+
+type refreshTokenRow struct {
+	accountID string
+	expiresAt time.Time
+	revoked   bool
+}
+
+func (row *refreshTokenRow) live(now time.Time) bool {
+	return !row.revoked && now.Before(row.expiresAt)
+}
+
+type refreshTokenRepo struct{ *state }
+
+func (r *refreshTokenRepo) Create(_ context.Context, t store.NewRefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.refreshTokens[t.Digest]; ok {
+		return store.ErrConflict
+	}
+	r.refreshTokens[t.Digest] = &refreshTokenRow{accountID: t.AccountID, expiresAt: t.ExpiresAt}
+	return nil
+}
+
+func (r *refreshTokenRepo) Rotate(_ context.Context, oldDigest, newDigest string, expiresAt time.Time) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.refreshTokens[oldDigest]
+	if !ok || !row.live(time.Now()) {
+		return "", store.ErrNotFound
+	}
+	if _, ok := r.refreshTokens[newDigest]; ok {
+		return "", store.ErrConflict
+	}
+	row.revoked = true
+	r.refreshTokens[newDigest] = &refreshTokenRow{accountID: row.accountID, expiresAt: expiresAt}
+	return row.accountID, nil
+}
+
+func (r *refreshTokenRepo) Revoke(_ context.Context, digest string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.refreshTokens[digest]
+	if !ok || !row.live(time.Now()) {
+		return "", store.ErrNotFound
+	}
+	row.revoked = true
+	return row.accountID, nil
+}
+
+func (r *refreshTokenRepo) RevokeAll(_ context.Context, accountID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	n := 0
+	for _, row := range r.refreshTokens {
+		if row.accountID == accountID && row.live(now) {
+			row.revoked = true
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+type roleRepo struct{ *state }
+
+func (r *roleRepo) ListForAccount(_ context.Context, accountID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	roles := []string{}
+	for role := range r.roles[accountID] {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+func (r *roleRepo) Grant(_ context.Context, accountID, role string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role = strings.ToLower(role)
+	if r.roles[accountID] == nil {
+		r.roles[accountID] = map[string]bool{}
+	}
+	if r.roles[accountID][role] {
+		return false, nil
+	}
+	r.roles[accountID][role] = true
+	return true, nil
+}
+
+func (r *roleRepo) Revoke(_ context.Context, accountID, role string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role = strings.ToLower(role)
+	if !r.roles[accountID][role] {
+		return false, nil
+	}
+	delete(r.roles[accountID], role)
+	return true, nil
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"graphql/graph/model"
+	"graphql/store"
+)
+
+type timelineRepo struct{ *state }
+
+func (r *timelineRepo) FanOut(_ context.Context, postID string, maxFollowers int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, ok := r.posts[postID]
+	if !ok || row.deleted || r.followerCount(row.post.AuthorID) > maxFollowers {
+		return 0, nil
+	}
+	n := 0
+	for key := range r.follows {
+		if key.followed == row.post.AuthorID && r.addToTimeline(key.follower, postID) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *timelineRepo) Backfill(_ context.Context, ownerID, authorID string, maxFollowers, limit int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.followerCount(authorID) > maxFollowers {
+		return nil
+	}
+	rows := []*postRow{}
+	for _, row := range r.posts {
+		if !row.deleted && row.post.AuthorID == authorID {
+			rows = append(rows, row)
+		}
+	}
+	sortNewestFirst(rows)
+	for i := 0; i < len(rows) && i < limit; i++ {
+		r.addToTimeline(ownerID, rows[i].post.PostID)
+	}
+	return nil
+}
+
+func (r *timelineRepo) Prune(_ context.Context, ownerID, authorID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for postID := range r.timelines[ownerID] {
+		if row, ok := r.posts[postID]; !ok || row.post.AuthorID == authorID {
+			delete(r.timelines[ownerID], postID)
+		}
+	}
+	return nil
+}
+
+func (r *timelineRepo) List(_ context.Context, ownerID string, maxFollowers int, after *store.Cursor, limit int) ([]*model.PostEdge, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rows := []*postRow{}
+	for _, row := range r.posts {
+		if row.deleted || !r.visibleTo(row.post.AuthorID, ownerID) || r.muted(ownerID, row.post.AuthorID) {
+			continue
+		}
+		_, following := r.follows[followKey{ownerID, row.post.AuthorID}]
+		onRead := following && r.followerCount(row.post.AuthorID) > maxFollowers
+		if !r.timelines[ownerID][row.post.PostID] && !onRead {
+			continue
+		}
+		if after != nil && !keysetBefore(row.createdAt, row.post.PostID, *after) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortNewestFirst(rows)
+	edges := []*model.PostEdge{}
+	for i := 0; i < len(rows) && len(edges) < limit; i++ {
+		cursor := store.Cursor{CreatedAt: rows[i].createdAt, ID: rows[i].post.PostID}
+		edges = append(edges, &model.PostEdge{Cursor: cursor.Encode(), Node: (&postRepo{r.state}).toModel(rows[i])})
+	}
+	return edges, nil
+}
+
+// addToTimeline reports whether postID was not yet on ownerID's timeline. Callers must hold mu.
+func (r *timelineRepo) addToTimeline(ownerID, postID string) bool {
+	if r.timelines[ownerID] == nil {
+		r.timelines[ownerID] = map[string]bool{}
+	}
+	if r.timelines[ownerID][postID] {
+		return false
+	}
+	r.timelines[ownerID][postID] = true
+	return true
+}
+
+// followerCount counts accountID's followers, matching accounts.follower_count. Callers must hold mu.
+func (s *state) followerCount(accountID string) int {
+	n := 0
+	for key := range s.follows {
+		if key.followed == accountID {
+			n++
+		}
+	}
+	return n
+}
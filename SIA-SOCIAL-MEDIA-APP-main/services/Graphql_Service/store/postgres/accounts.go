@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// accountColumns is the column list scanned by scanAccount.
+const accountColumns = `id, email, first_name, last_name, address, phone, age, gender, is_private, created_at, updated_at`
+
+type accountRepo struct{ *conn }
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// withExtra scans columns selected after the ones its rowScanner's caller knows about into extra.
+type withExtra struct {
+	rowScanner
+	extra []any
+}
+
+func (w withExtra) Scan(dest ...any) error {
+	return w.rowScanner.Scan(append(dest, w.extra...)...)
+}
+
+func scanAccount(row rowScanner) (*model.Account, store.Cursor, error) {
+	var acc model.Account
+	var createdAt time.Time
+	var updatedAt sql.NullTime
+	if err := row.Scan(&acc.AccountID, &acc.Email, &acc.FirstName, &acc.LastName, &acc.Address, &acc.Phone, &acc.Age, &acc.Gender, &acc.IsPrivate, &createdAt, &updatedAt); err != nil {
+		return nil, store.Cursor{}, err
+	}
+	acc.CreatedAt = formatTime(createdAt)
+	acc.UpdatedAt = formatNullTime(updatedAt)
+	return &acc, store.Cursor{CreatedAt: createdAt, ID: acc.AccountID}, nil
+}
+
+func (r *accountRepo) Create(ctx context.Context, input store.NewAccount) (*model.Account, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO accounts (email, password, first_name, last_name, address, phone, age, gender, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING `+accountColumns,
+		input.Email, input.PasswordHash, input.FirstName, input.LastName, input.Address, input.Phone, input.Age, input.Gender)
+	acc, _, err := scanAccount(row)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, store.ErrConflict
+		}
+		return nil, fmt.Errorf("insert account: %w", err)
+	}
+	return acc, nil
+}
+
+func (r *accountRepo) GetByID(ctx context.Context, accountID string) (*model.Account, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	acc, _, err := scanAccount(r.db.QueryRowContext(ctx, `SELECT `+accountColumns+` FROM accounts WHERE id = $1`, accountID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return acc, nil
+}
+
+func (r *accountRepo) GetByIDs(ctx context.Context, accountIDs []string) (map[string]*model.Account, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `SELECT `+accountColumns+` FROM accounts WHERE id = ANY($1::uuid[])`, pq.Array(accountIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make(map[string]*model.Account, len(accountIDs))
+	for rows.Next() {
+		acc, _, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts[acc.AccountID] = acc
+	}
+	return accounts, rows.Err()
+}
+
+func (r *accountRepo) GetCredentials(ctx context.Context, email string) (string, string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var accountID, passwordHash string
+	err := r.db.QueryRowContext(ctx, `SELECT id, password FROM accounts WHERE email = $1`, email).Scan(&accountID, &passwordHash)
+	if err != nil {
+		return "", "", notFound(err)
+	}
+	return accountID, passwordHash, nil
+}
+
+func (r *accountRepo) UpdatePasswordHash(ctx context.Context, accountID, passwordHash string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, `UPDATE accounts SET password = $2, updated_at = NOW() WHERE id = $1`, accountID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (r *accountRepo) SetPrivate(ctx context.Context, accountID string, private bool) (*model.Account, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	acc, _, err := scanAccount(r.db.QueryRowContext(ctx, `
+		UPDATE accounts SET is_private = $2, updated_at = NOW() WHERE id = $1
+		RETURNING `+accountColumns, accountID, private))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return acc, nil
+}
+
+func (r *accountRepo) List(ctx context.Context, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	args := []any{}
+	keyset := keysetBefore("created_at", "id", after, &args)
+	args = append(args, limit)
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE TRUE` + keyset + fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args))
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.AccountEdge{}
+	for rows.Next() {
+		acc, cursor, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, &model.AccountEdge{Cursor: cursor.Encode(), Node: acc})
+	}
+	return edges, rows.Err()
+}
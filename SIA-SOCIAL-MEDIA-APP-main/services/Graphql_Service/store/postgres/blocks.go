@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+)
+
+type blockRepo struct{ *conn }
+
+func (r *blockRepo) Block(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	return r.exec(ctx, "insert block", `INSERT INTO account_blocks (blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, blockerID, blockedID)
+}
+
+func (r *blockRepo) Unblock(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	return r.exec(ctx, "delete block", `DELETE FROM account_blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID)
+}
+
+func (r *blockRepo) IsBlocking(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM account_blocks WHERE blocker_id = $1 AND blocked_id = $2)`, blockerID, blockedID).Scan(&exists)
+	return exists, err
+}
+
+func (r *blockRepo) ListBlocked(ctx context.Context, blockerID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listRelated(ctx, "account_blocks", "blocker_id", "blocked_id", blockerID, after, limit)
+}
+
+func (r *blockRepo) Mute(ctx context.Context, muterID, mutedID string) (bool, error) {
+	return r.exec(ctx, "insert mute", `INSERT INTO account_mutes (muter_id, muted_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, muterID, mutedID)
+}
+
+func (r *blockRepo) Unmute(ctx context.Context, muterID, mutedID string) (bool, error) {
+	return r.exec(ctx, "delete mute", `DELETE FROM account_mutes WHERE muter_id = $1 AND muted_id = $2`, muterID, mutedID)
+}
+
+func (r *blockRepo) ListMuted(ctx context.Context, muterID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listRelated(ctx, "account_mutes", "muter_id", "muted_id", muterID, after, limit)
+}
+
+// exec runs a single-row write and reports whether it changed anything.
+func (r *blockRepo) exec(ctx context.Context, what, query string, args ...any) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", what, err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// notBlocked returns a condition excluding rows where the accounts in columns a and b block each other in
+// either direction. Either side may be a column or a uuid parameter such as "$1::uuid"; a NULL parameter
+// blocks nothing.
+func notBlocked(a, b string) string {
+	return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM account_blocks bl
+			WHERE (bl.blocker_id = %[1]s AND bl.blocked_id = %[2]s)
+			OR (bl.blocker_id = %[2]s AND bl.blocked_id = %[1]s))`, a, b)
+}
+
+// notMuted returns a condition excluding rows where the account in muterColumn muted the one in mutedColumn.
+// Like notBlocked, either side may be a uuid parameter.
+func notMuted(muterColumn, mutedColumn string) string {
+	return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM account_mutes mu WHERE mu.muter_id = %s AND mu.muted_id = %s)`, muterColumn, mutedColumn)
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"strings"
+	"time"
+)
+
+// commentColumns selects a comment (alias c). Deleted comments read as empty content.
+const commentColumns = `c.comment_id, c.post_id, c.parent_comment_id, c.author_id,
+	CASE WHEN c.deleted_at IS NULL THEN c.content ELSE '' END,
+	c.created_at, c.updated_at, c.deleted_at`
+
+type commentRepo struct{ *conn }
+
+func scanComment(row rowScanner) (*model.Comment, store.Cursor, error) {
+	var c model.Comment
+	var createdAt time.Time
+	var updatedAt, deletedAt sql.NullTime
+	var parentID sql.NullString
+	if err := row.Scan(&c.CommentID, &c.PostID, &parentID, &c.AuthorID, &c.Content, &createdAt, &updatedAt, &deletedAt); err != nil {
+		return nil, store.Cursor{}, err
+	}
+	c.ParentCommentID = nullString(parentID)
+	c.CreatedAt = formatTime(createdAt)
+	c.UpdatedAt = formatNullTime(updatedAt)
+	c.DeletedAt = formatNullTime(deletedAt)
+	return &c, store.Cursor{CreatedAt: createdAt, ID: c.CommentID}, nil
+}
+
+// queryComment runs a statement returning one row of commentColumns.
+func (r *commentRepo) queryComment(ctx context.Context, query string, args ...any) (*model.Comment, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	c, _, err := scanComment(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return c, nil
+}
+
+func (r *commentRepo) Create(ctx context.Context, input store.NewComment) (*model.Comment, error) {
+	var parentID any
+	if input.ParentID != "" {
+		parentID = input.ParentID
+	}
+	// Inserting through a SELECT makes the post/parent checks and the insert one atomic statement.
+	return r.queryComment(ctx, `
+		WITH c AS (
+			INSERT INTO comments (post_id, parent_comment_id, author_id, content)
+			SELECT p.post_id, $2::uuid, $3, $4 FROM posts p
+			WHERE p.post_id = $1 AND p.deleted_at IS NULL
+				AND ($2::uuid IS NULL OR EXISTS (
+					SELECT 1 FROM comments pc WHERE pc.comment_id = $2::uuid AND pc.post_id = p.post_id AND pc.deleted_at IS NULL))
+			RETURNING *
+		)
+		SELECT `+commentColumns+` FROM c`,
+		input.PostID, parentID, input.AuthorID, input.Content)
+}
+
+func (r *commentRepo) GetByID(ctx context.Context, commentID string) (*model.Comment, error) {
+	return r.queryComment(ctx, `SELECT `+commentColumns+` FROM comments c WHERE c.comment_id = $1`, commentID)
+}
+
+func (r *commentRepo) Update(ctx context.Context, commentID, authorID, content string) (*model.Comment, error) {
+	return r.queryComment(ctx, `
+		WITH c AS (
+			UPDATE comments SET content = $3, updated_at = NOW()
+			WHERE comment_id = $1 AND author_id = $2 AND deleted_at IS NULL
+			RETURNING *
+		)
+		SELECT `+commentColumns+` FROM c`,
+		commentID, authorID, content)
+}
+
+func (r *commentRepo) Delete(ctx context.Context, commentID string) (*model.Comment, error) {
+	return r.queryComment(ctx, `
+		WITH c AS (
+			UPDATE comments SET deleted_at = NOW()
+			WHERE comment_id = $1 AND deleted_at IS NULL
+			RETURNING *
+		)
+		SELECT `+commentColumns+` FROM c`,
+		commentID)
+}
+
+func (r *commentRepo) List(ctx context.Context, postID, parentID string, after *store.Cursor, limit int) ([]*model.CommentEdge, error) {
+	var queryBuilder strings.Builder
+	args := []any{postID}
+	queryBuilder.WriteString(`SELECT ` + commentColumns + ` FROM comments c WHERE c.post_id = $1`)
+	if parentID == "" {
+		queryBuilder.WriteString(" AND c.parent_comment_id IS NULL")
+	} else {
+		args = append(args, parentID)
+		fmt.Fprintf(&queryBuilder, " AND c.parent_comment_id = $%d", len(args))
+	}
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		fmt.Fprintf(&queryBuilder, " AND (c.created_at, c.comment_id) > ($%d, $%d::uuid)", len(args)-1, len(args))
+	}
+	args = append(args, limit)
+	fmt.Fprintf(&queryBuilder, " ORDER BY c.created_at, c.comment_id LIMIT $%d", len(args))
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, queryBuilder.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.CommentEdge{}
+	for rows.Next() {
+		c, cursor, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, &model.CommentEdge{Cursor: cursor.Encode(), Node: c})
+	}
+	return edges, rows.Err()
+}
+
+func (r *commentRepo) CountForPost(ctx context.Context, postID string) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comments WHERE post_id = $1 AND deleted_at IS NULL`, postID).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type followRequestRepo struct{ *conn }
+
+func (r *followRequestRepo) Create(ctx context.Context, requesterID, targetID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO follow_requests (requester_id, target_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, requesterID, targetID)
+	if err != nil {
+		return false, fmt.Errorf("insert follow request: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *followRequestRepo) Delete(ctx context.Context, requesterID, targetID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM follow_requests WHERE requester_id = $1 AND target_id = $2`, requesterID, targetID)
+	if err != nil {
+		return false, fmt.Errorf("delete follow request: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *followRequestRepo) Approve(ctx context.Context, requesterID, targetID string) (bool, error) {
+	ids, err := r.approve(ctx, `requester_id = $2 AND target_id = $1`, targetID, requesterID)
+	return len(ids) > 0, err
+}
+
+func (r *followRequestRepo) ApproveAll(ctx context.Context, targetID string) ([]string, error) {
+	return r.approve(ctx, `target_id = $1`, targetID)
+}
+
+// approve deletes the requests to the target $1 matching condition and inserts the follows and counts in one
+// statement, like followRepo.Follow. It returns the requesters who were not already following.
+func (r *followRequestRepo) approve(ctx context.Context, condition string, args ...any) ([]string, error) {
+	ids, err := r.queryIDs(ctx, `
+		WITH req AS (
+			DELETE FROM follow_requests WHERE `+condition+` RETURNING requester_id
+		), f AS (
+			INSERT INTO follows (follower_user_id, followed_user_id) SELECT requester_id, $1 FROM req
+			ON CONFLICT DO NOTHING
+			RETURNING follower_user_id
+		), counts AS (
+			UPDATE accounts a SET
+				follower_count = a.follower_count + CASE WHEN a.id = $1 THEN (SELECT COUNT(*) FROM f) ELSE 0 END,
+				following_count = a.following_count + CASE WHEN a.id = $1 THEN 0 ELSE 1 END
+			WHERE a.id = $1 OR a.id IN (SELECT follower_user_id FROM f)
+		)
+		SELECT follower_user_id FROM f`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("approve follow requests: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *followRequestRepo) ListPending(ctx context.Context, targetID string, after *store.Cursor, limit int) ([]*model.FollowRequestEdge, error) {
+	args := []any{targetID}
+	keyset := keysetBefore("requested_at", "id", after, &args)
+	args = append(args, limit)
+	query := `SELECT ` + accountColumns + `, requested_at FROM (
+			SELECT a.*, fr.created_at AS requested_at FROM follow_requests fr JOIN accounts a ON a.id = fr.requester_id
+			WHERE fr.target_id = $1
+		) fa WHERE TRUE` + keyset + fmt.Sprintf(` ORDER BY requested_at DESC, id DESC LIMIT $%d`, len(args))
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.FollowRequestEdge{}
+	for rows.Next() {
+		var requestedAt time.Time
+		acc, _, err := scanAccount(withExtra{rows, []any{&requestedAt}})
+		if err != nil {
+			return nil, err
+		}
+		cursor := store.Cursor{CreatedAt: requestedAt, ID: acc.AccountID}
+		edges = append(edges, &model.FollowRequestEdge{
+			Cursor: cursor.Encode(),
+			Node:   &model.FollowRequest{Requester: acc, CreatedAt: formatTime(requestedAt)},
+		})
+	}
+	return edges, rows.Err()
+}
+
+func (r *followRequestRepo) RequestedAmong(ctx context.Context, requesterID string, targetIDs []string) (map[string]bool, error) {
+	ids, err := r.queryIDs(ctx, `SELECT target_id FROM follow_requests WHERE requester_id = $1 AND target_id = ANY($2::uuid[])`, requesterID, pq.Array(targetIDs))
+	if err != nil {
+		return nil, err
+	}
+	requested := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		requested[id] = true
+	}
+	return requested, nil
+}
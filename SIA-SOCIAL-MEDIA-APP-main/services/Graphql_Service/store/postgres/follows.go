@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type followRepo struct{ *conn }
+
+func (r *followRepo) Follow(ctx context.Context, followerID, followedID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	// The counts only move when a row was actually inserted, in the same statement.
+	result, err := r.db.ExecContext(ctx, `
+		WITH f AS (
+			INSERT INTO follows (follower_user_id, followed_user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+			RETURNING follower_user_id, followed_user_id
+		)
+		UPDATE accounts a SET
+			follower_count = a.follower_count + (a.id = f.followed_user_id)::int,
+			following_count = a.following_count + (a.id = f.follower_user_id)::int
+		FROM f WHERE a.id IN (f.follower_user_id, f.followed_user_id)`,
+		followerID, followedID)
+	if err != nil {
+		return false, fmt.Errorf("insert follow: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+func (r *followRepo) Unfollow(ctx context.Context, followerID, followedID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, `
+		WITH f AS (
+			DELETE FROM follows WHERE follower_user_id = $1 AND followed_user_id = $2
+			RETURNING follower_user_id, followed_user_id
+		)
+		UPDATE accounts a SET
+			follower_count = GREATEST(a.follower_count - (a.id = f.followed_user_id)::int, 0),
+			following_count = GREATEST(a.following_count - (a.id = f.follower_user_id)::int, 0)
+		FROM f WHERE a.id IN (f.follower_user_id, f.followed_user_id)`,
+		followerID, followedID)
+	if err != nil {
+		return false, fmt.Errorf("delete follow: %w", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+func (r *followRepo) IsFollowing(ctx context.Context, followerID, followedID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM follows WHERE follower_user_id = $1 AND followed_user_id = $2)`, followerID, followedID).Scan(&exists)
+	return exists, err
+}
+
+func (r *followRepo) FollowedAmong(ctx context.Context, followerID string, accountIDs []string) (map[string]bool, error) {
+	ids, err := r.queryIDs(ctx, `SELECT followed_user_id FROM follows WHERE follower_user_id = $1 AND followed_user_id = ANY($2::uuid[])`, followerID, pq.Array(accountIDs))
+	if err != nil {
+		return nil, err
+	}
+	followed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		followed[id] = true
+	}
+	return followed, nil
+}
+
+func (r *followRepo) FollowersAmong(ctx context.Context, followedID string, accountIDs []string) (map[string]bool, error) {
+	ids, err := r.queryIDs(ctx, `SELECT follower_user_id FROM follows WHERE followed_user_id = $1 AND follower_user_id = ANY($2::uuid[])`, followedID, pq.Array(accountIDs))
+	if err != nil {
+		return nil, err
+	}
+	followers := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		followers[id] = true
+	}
+	return followers, nil
+}
+
+func (r *followRepo) FollowerIDs(ctx context.Context, accountID string) ([]string, error) {
+	return r.queryIDs(ctx, `SELECT follower_user_id FROM follows WHERE followed_user_id = $1`, accountID)
+}
+
+func (r *followRepo) FollowingIDs(ctx context.Context, accountID string) ([]string, error) {
+	return r.queryIDs(ctx, `SELECT followed_user_id FROM follows WHERE follower_user_id = $1`, accountID)
+}
+
+func (r *followRepo) Followers(ctx context.Context, accountID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listRelated(ctx, "follows", "followed_user_id", "follower_user_id", accountID, after, limit)
+}
+
+func (r *followRepo) Following(ctx context.Context, accountID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	return r.listRelated(ctx, "follows", "follower_user_id", "followed_user_id", accountID, after, limit)
+}
+
+// listRelated pages through the accounts in otherColumn of the rows of table whose ownerColumn is accountID,
+// newest row first. table must have a created_at column; follows, blocks and mutes all do.
+func (c *conn) listRelated(ctx context.Context, table, ownerColumn, otherColumn, accountID string, after *store.Cursor, limit int) ([]*model.AccountEdge, error) {
+	args := []any{accountID}
+	keyset := keysetBefore("followed_at", "id", after, &args)
+	args = append(args, limit)
+	query := `SELECT ` + accountColumns + `, followed_at FROM (
+			SELECT a.*, f.created_at AS followed_at FROM ` + table + ` f JOIN accounts a ON a.id = f.` + otherColumn + `
+			WHERE f.` + ownerColumn + ` = $1
+		) fa WHERE TRUE` + keyset + fmt.Sprintf(` ORDER BY followed_at DESC, id DESC LIMIT $%d`, len(args))
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.AccountEdge{}
+	for rows.Next() {
+		var followedAt time.Time
+		acc, _, err := scanAccount(withExtra{rows, []any{&followedAt}})
+		if err != nil {
+			return nil, err
+		}
+		cursor := store.Cursor{CreatedAt: followedAt, ID: acc.AccountID}
+		edges = append(edges, &model.AccountEdge{Cursor: cursor.Encode(), Node: acc})
+	}
+	return edges, rows.Err()
+}
+
+func (r *followRepo) Counts(ctx context.Context, accountIDs []string) (map[string]store.FollowCounts, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `SELECT id, follower_count, following_count FROM accounts WHERE id = ANY($1::uuid[])`, pq.Array(accountIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]store.FollowCounts, len(accountIDs))
+	for rows.Next() {
+		var id string
+		var c store.FollowCounts
+		if err := rows.Scan(&id, &c.Followers, &c.Following); err != nil {
+			return nil, err
+		}
+		counts[id] = c
+	}
+	return counts, rows.Err()
+}
+
+// Suggest walks two hops of the follow graph in one aggregate; the (follower, created_at) indexes keep each
+// hop an index scan. Results are meant to be cached by the caller.
+func (r *followRepo) Suggest(ctx context.Context, viewerID string, limit int) ([]store.Suggestion, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT f2.followed_user_id, COUNT(*) AS mutual_count,
+			(array_agg(f2.follower_user_id::text ORDER BY f2.created_at DESC, f2.follower_user_id DESC))[1:%d]
+		FROM follows f1
+		JOIN follows f2 ON f2.follower_user_id = f1.followed_user_id
+		WHERE f1.follower_user_id = $1
+			AND f2.followed_user_id <> $1
+			AND NOT EXISTS (SELECT 1 FROM follows ff WHERE ff.follower_user_id = $1 AND ff.followed_user_id = f2.followed_user_id)
+			AND NOT EXISTS (SELECT 1 FROM follow_requests fr WHERE fr.requester_id = $1 AND fr.target_id = f2.followed_user_id)
+			AND %s AND %s
+		GROUP BY f2.followed_user_id
+		ORDER BY mutual_count DESC, f2.followed_user_id
+		LIMIT $2`, store.SuggestionMutuals, notBlocked("f2.followed_user_id", "$1::uuid"), notMuted("$1::uuid", "f2.followed_user_id")),
+		viewerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []store.Suggestion{}
+	for rows.Next() {
+		var s store.Suggestion
+		if err := rows.Scan(&s.AccountID, &s.MutualCount, pq.Array(&s.MutualIDs)); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
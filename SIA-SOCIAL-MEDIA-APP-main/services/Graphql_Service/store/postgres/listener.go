@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/pubsub"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// The notifications table triggers publish on these NOTIFY channels: every new row on
+// notificationAddedChannel, and every recipient whose notifications were marked read on
+// notificationReadChannel.
+const (
+	notificationAddedChannel = "notification_added"
+	notificationReadChannel  = "notification_read"
+)
+
+// notificationListener turns NOTIFY events into notifications and unread counts for this process's
+// subscribers. Every replica runs its own, so a change made through any replica reaches subscribers on all.
+type notificationListener struct {
+	url           string
+	notifications *pubsub.Hub[*model.Notification]
+	unreadCounts  *pubsub.Hub[int]
+	once          sync.Once
+}
+
+func newNotificationListener(url string) *notificationListener {
+	return &notificationListener{
+		url:           url,
+		notifications: pubsub.NewHub[*model.Notification](),
+		unreadCounts:  pubsub.NewHub[int](),
+	}
+}
+
+// start opens the LISTEN connection once. It returns immediately; pq.Listener reconnects on its own.
+func (l *notificationListener) start(repo *notificationRepo) {
+	l.once.Do(func() {
+		go listen(l.url, "notificationListener", []string{notificationAddedChannel, notificationReadChannel},
+			func(channel, payload string) { l.deliver(repo, channel, payload) })
+	})
+}
+
+// deliver loads what a trigger payload refers to, but only for recipients with subscribers on this replica.
+func (l *notificationListener) deliver(repo *notificationRepo, channel, payload string) {
+	var event struct {
+		NotificationID string `json:"notification_id"`
+		RecipientID    string `json:"recipient_user_id"`
+	}
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		log.Printf("notificationListener: malformed %s payload %q: %v", channel, payload, err)
+		return
+	}
+	ctx := context.Background()
+
+	if channel == notificationAddedChannel && l.notifications.HasSubscribers(event.RecipientID) {
+		notif, err := repo.GetByID(ctx, event.NotificationID)
+		if err != nil {
+			log.Printf("notificationListener: failed to load notification %s: %v", event.NotificationID, err)
+		} else {
+			l.notifications.Publish(event.RecipientID, notif)
+		}
+	}
+	if l.unreadCounts.HasSubscribers(event.RecipientID) {
+		n, err := repo.CountUnread(ctx, event.RecipientID)
+		if err != nil {
+			log.Printf("notificationListener: failed to count unread notifications of %s: %v", event.RecipientID, err)
+			return
+		}
+		l.unreadCounts.Publish(event.RecipientID, n)
+	}
+}
+
+// listen opens a LISTEN connection to url on channels and hands every event to handle, one at a time. It runs
+// for the life of the process; name prefixes its log lines. Events sent while disconnected are lost, so
+// clients catch up through the matching queries.
+func listen(url, name string, channels []string, handle func(channel, payload string)) {
+	pl := openListener(url, name, channels)
+	log.Printf("%s: listening on %s", name, strings.Join(channels, " and "))
+	for {
+		select {
+		case n := <-pl.Notify:
+			if n == nil {
+				log.Printf("%s: reconnected to database", name)
+				continue
+			}
+			handle(n.Channel, n.Extra)
+		case <-time.After(90 * time.Second):
+			go pl.Ping()
+		}
+	}
+}
+
+// openListener returns a pq.Listener subscribed to channels. pq.Listener waits out lost connections itself,
+// but a LISTEN the server rejects is final for that listener, so it starts over with a new one, backing off
+// up to a minute between attempts.
+func openListener(url, name string, channels []string) *pq.Listener {
+	for backoff := time.Second; ; backoff = min(2*backoff, time.Minute) {
+		pl := pq.NewListener(url, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Printf("%s: connection event %d: %v", name, ev, err)
+			}
+		})
+		err := listenAll(pl, channels)
+		if err == nil {
+			return pl
+		}
+		pl.Close()
+		log.Printf("%s: %v; live updates are paused, retrying in %s", name, err, backoff)
+		time.Sleep(backoff)
+	}
+}
+
+func listenAll(pl *pq.Listener, channels []string) error {
+	for _, channel := range channels {
+		if err := pl.Listen(channel); err != nil {
+			return fmt.Errorf("LISTEN %s failed: %w", channel, err)
+		}
+	}
+	return nil
+}
+
+// messageChangedChannel carries {message_id, conversation_id} for every message sent, edited or deleted.
+const messageChangedChannel = "message_changed"
+
+// messageListener turns NOTIFY events into messages for this process's subscribers, keyed by member ID.
+type messageListener struct {
+	url      string
+	messages *pubsub.Hub[*model.Message]
+	once     sync.Once
+}
+
+func newMessageListener(url string) *messageListener {
+	return &messageListener{url: url, messages: pubsub.NewHub[*model.Message]()}
+}
+
+// start opens the LISTEN connection once. It returns immediately; pq.Listener reconnects on its own.
+func (l *messageListener) start(repo *messageRepo) {
+	l.once.Do(func() {
+		go listen(l.url, "messageListener", []string{messageChangedChannel},
+			func(channel, payload string) { l.deliver(repo, payload) })
+	})
+}
+
+// deliver loads the changed message, but only when a member who may see it has subscribers on this replica.
+func (l *messageListener) deliver(repo *messageRepo, payload string) {
+	var event struct {
+		MessageID      string `json:"message_id"`
+		ConversationID string `json:"conversation_id"`
+		SenderID       string `json:"sender_id"`
+	}
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		log.Printf("messageListener: malformed %s payload %q: %v", messageChangedChannel, payload, err)
+		return
+	}
+	ctx := context.Background()
+
+	memberIDs, err := repo.recipients(ctx, event.ConversationID, event.SenderID)
+	if err != nil {
+		log.Printf("messageListener: failed to list members of conversation %s: %v", event.ConversationID, err)
+		return
+	}
+	memberIDs = slices.DeleteFunc(memberIDs, func(id string) bool { return !l.messages.HasSubscribers(id) })
+	if len(memberIDs) == 0 {
+		return
+	}
+	msg, err := repo.queryMessage(ctx, `SELECT `+messageColumns+` FROM messages m WHERE m.message_id = $1`, event.MessageID)
+	if err != nil {
+		log.Printf("messageListener: failed to load message %s: %v", event.MessageID, err)
+		return
+	}
+	for _, id := range memberIDs {
+		l.messages.Publish(id, msg)
+	}
+}
@@ -0,0 +1,321 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type messageRepo struct {
+	*conn
+	listener *messageListener
+}
+
+// conversationColumns selects a conversation (alias c) in scanConversation order.
+const conversationColumns = `c.conversation_id, c.is_group, c.title, c.created_at, c.last_message_at`
+
+// messageColumns selects a message (alias m) in scanMessage order.
+const messageColumns = `m.message_id, m.conversation_id, m.sender_id, m.body, m.created_at, m.edited_at, m.deleted_at`
+
+func scanConversation(row rowScanner) (*model.Conversation, store.Cursor, error) {
+	var c model.Conversation
+	var title sql.NullString
+	var createdAt, lastMessageAt time.Time
+	if err := row.Scan(&c.ConversationID, &c.IsGroup, &title, &createdAt, &lastMessageAt); err != nil {
+		return nil, store.Cursor{}, err
+	}
+	c.Title = nullString(title)
+	c.CreatedAt = formatTime(createdAt)
+	c.LastMessageAt = formatTime(lastMessageAt)
+	return &c, store.Cursor{CreatedAt: lastMessageAt, ID: c.ConversationID}, nil
+}
+
+func scanMessage(row rowScanner) (*model.Message, store.Cursor, error) {
+	var m model.Message
+	var body sql.NullString
+	var createdAt time.Time
+	var editedAt, deletedAt sql.NullTime
+	if err := row.Scan(&m.MessageID, &m.ConversationID, &m.SenderID, &body, &createdAt, &editedAt, &deletedAt); err != nil {
+		return nil, store.Cursor{}, err
+	}
+	m.Body = nullString(body)
+	m.CreatedAt = formatTime(createdAt)
+	m.EditedAt = formatNullTime(editedAt)
+	m.DeletedAt = formatNullTime(deletedAt)
+	return &m, store.Cursor{CreatedAt: createdAt, ID: m.MessageID}, nil
+}
+
+// queryConversation runs a statement returning one row of conversationColumns.
+func (r *messageRepo) queryConversation(ctx context.Context, query string, args ...any) (*model.Conversation, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	c, _, err := scanConversation(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return c, nil
+}
+
+// queryMessage runs a statement returning one row of messageColumns.
+func (r *messageRepo) queryMessage(ctx context.Context, query string, args ...any) (*model.Message, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	m, _, err := scanMessage(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return m, nil
+}
+
+func (r *messageRepo) CreateConversation(ctx context.Context, c store.NewConversation) (*model.Conversation, error) {
+	var directKey any
+	if !c.IsGroup {
+		ids := slices.Sorted(slices.Values(c.MemberIDs))
+		directKey = ids[0] + ":" + ids[1]
+	}
+	// The conversation and its members go in together; a direct conversation that already exists for the pair
+	// makes the insert a no-op.
+	conv, err := r.queryConversation(ctx, `
+		WITH c AS (
+			INSERT INTO conversations (is_group, title, direct_key) VALUES ($1, $2, $3)
+			ON CONFLICT (direct_key) DO NOTHING
+			RETURNING *
+		), m AS (
+			INSERT INTO conversation_members (conversation_id, account_id)
+			SELECT c.conversation_id, unnest($4::uuid[]) FROM c
+		)
+		SELECT `+conversationColumns+` FROM c`,
+		c.IsGroup, c.Title, directKey, pq.Array(c.MemberIDs))
+	if errors.Is(err, store.ErrNotFound) && directKey != nil {
+		return r.queryConversation(ctx, `SELECT `+conversationColumns+` FROM conversations c WHERE c.direct_key = $1`, directKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("insert conversation: %w", err)
+	}
+	return conv, nil
+}
+
+func (r *messageRepo) GetConversation(ctx context.Context, conversationID, memberID string) (*model.Conversation, error) {
+	return r.queryConversation(ctx, `SELECT `+conversationColumns+` FROM conversations c
+		JOIN conversation_members cm ON cm.conversation_id = c.conversation_id AND cm.account_id = $2
+		WHERE c.conversation_id = $1`, conversationID, memberID)
+}
+
+func (r *messageRepo) ListConversations(ctx context.Context, memberID string, after *store.Cursor, limit int) ([]*model.ConversationEdge, error) {
+	args := []any{memberID}
+	keyset := keysetBefore("c.last_message_at", "c.conversation_id", after, &args)
+	args = append(args, limit)
+	query := `SELECT ` + conversationColumns + ` FROM conversations c
+		JOIN conversation_members cm ON cm.conversation_id = c.conversation_id
+		WHERE cm.account_id = $1` + keyset + fmt.Sprintf(` ORDER BY c.last_message_at DESC, c.conversation_id DESC LIMIT $%d`, len(args))
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.ConversationEdge{}
+	for rows.Next() {
+		c, cursor, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, &model.ConversationEdge{Cursor: cursor.Encode(), Node: c})
+	}
+	return edges, rows.Err()
+}
+
+func (r *messageRepo) Members(ctx context.Context, conversationIDs []string) (map[string][]store.ConversationMember, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT conversation_id, account_id, COALESCE(last_read_message_id::text, ''), last_read_at
+		FROM conversation_members WHERE conversation_id = ANY($1::uuid[])
+		ORDER BY joined_at, account_id`, pq.Array(conversationIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := map[string][]store.ConversationMember{}
+	for rows.Next() {
+		var conversationID string
+		var m store.ConversationMember
+		var lastReadAt sql.NullTime
+		if err := rows.Scan(&conversationID, &m.AccountID, &m.LastReadMessageID, &lastReadAt); err != nil {
+			return nil, err
+		}
+		m.LastReadAt = lastReadAt.Time
+		members[conversationID] = append(members[conversationID], m)
+	}
+	return members, rows.Err()
+}
+
+func (r *messageRepo) LastMessages(ctx context.Context, viewerID string, conversationIDs []string) (map[string]*model.Message, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	// One index range scan per conversation, stopping at the first visible message.
+	rows, err := r.db.QueryContext(ctx, `SELECT `+messageColumns+` FROM unnest($2::uuid[]) AS cid(id)
+		CROSS JOIN LATERAL (
+			SELECT * FROM messages m WHERE m.conversation_id = cid.id AND `+notBlocked("m.sender_id", "$1::uuid")+`
+			ORDER BY m.created_at DESC, m.message_id DESC LIMIT 1
+		) m`, viewerID, pq.Array(conversationIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := map[string]*model.Message{}
+	for rows.Next() {
+		m, _, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages[m.ConversationID] = m
+	}
+	return messages, rows.Err()
+}
+
+func (r *messageRepo) UnreadCounts(ctx context.Context, memberID string, conversationIDs []string) (map[string]int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT cm.conversation_id, COUNT(m.message_id) FROM conversation_members cm
+		LEFT JOIN messages m ON m.conversation_id = cm.conversation_id
+			AND m.sender_id <> cm.account_id AND m.deleted_at IS NULL
+			AND (cm.last_read_at IS NULL OR m.created_at > cm.last_read_at)
+			AND `+notBlocked("m.sender_id", "cm.account_id")+`
+		WHERE cm.account_id = $1 AND cm.conversation_id = ANY($2::uuid[])
+		GROUP BY cm.conversation_id`, memberID, pq.Array(conversationIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var id string
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+func (r *messageRepo) MarkRead(ctx context.Context, conversationID, memberID, messageID string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	// The position only moves forward, so marking an older message read is a no-op rather than an error.
+	var found int
+	err := r.db.QueryRowContext(ctx, `
+		WITH m AS (
+			SELECT message_id, created_at FROM messages
+			WHERE conversation_id = $1 AND ($3 = '' OR message_id::text = $3)
+			ORDER BY created_at DESC, message_id DESC LIMIT 1
+		), u AS (
+			UPDATE conversation_members cm SET last_read_message_id = m.message_id, last_read_at = m.created_at
+			FROM m
+			WHERE cm.conversation_id = $1 AND cm.account_id = $2
+				AND (cm.last_read_at IS NULL OR (m.created_at, m.message_id) > (cm.last_read_at, cm.last_read_message_id))
+		)
+		SELECT COUNT(*) FROM m`, conversationID, memberID, messageID).Scan(&found)
+	if err != nil {
+		return fmt.Errorf("mark conversation read: %w", err)
+	}
+	if found == 0 && messageID != "" {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (r *messageRepo) Send(ctx context.Context, conversationID, senderID, body string) (*model.Message, error) {
+	// Membership check, insert, conversation ordering and the sender's read position in one statement.
+	return r.queryMessage(ctx, `
+		WITH c AS (
+			UPDATE conversations SET last_message_at = NOW()
+			WHERE conversation_id = $1
+				AND EXISTS (SELECT 1 FROM conversation_members WHERE conversation_id = $1 AND account_id = $2)
+			RETURNING conversation_id, last_message_at
+		), m AS (
+			INSERT INTO messages (conversation_id, sender_id, body, created_at)
+			SELECT conversation_id, $2, $3, last_message_at FROM c
+			RETURNING *
+		), r AS (
+			UPDATE conversation_members cm SET last_read_message_id = m.message_id, last_read_at = m.created_at
+			FROM m WHERE cm.conversation_id = m.conversation_id AND cm.account_id = m.sender_id
+		)
+		SELECT `+messageColumns+` FROM m`,
+		conversationID, senderID, body)
+}
+
+func (r *messageRepo) GetMessage(ctx context.Context, messageID, memberID string) (*model.Message, error) {
+	return r.queryMessage(ctx, `SELECT `+messageColumns+` FROM messages m
+		WHERE m.message_id = $1
+			AND EXISTS (SELECT 1 FROM conversation_members cm WHERE cm.conversation_id = m.conversation_id AND cm.account_id = $2)`,
+		messageID, memberID)
+}
+
+func (r *messageRepo) Edit(ctx context.Context, messageID, senderID, body string) (*model.Message, error) {
+	return r.queryMessage(ctx, `UPDATE messages m SET body = $3, edited_at = NOW()
+		WHERE m.message_id = $1 AND m.sender_id = $2 AND m.deleted_at IS NULL
+		RETURNING `+messageColumns, messageID, senderID, body)
+}
+
+func (r *messageRepo) Delete(ctx context.Context, messageID, senderID string) (*model.Message, error) {
+	return r.queryMessage(ctx, `UPDATE messages m SET body = NULL, deleted_at = NOW()
+		WHERE m.message_id = $1 AND m.sender_id = $2 AND m.deleted_at IS NULL
+		RETURNING `+messageColumns, messageID, senderID)
+}
+
+func (r *messageRepo) ListMessages(ctx context.Context, conversationID, viewerID string, after *store.Cursor, limit int) ([]*model.MessageEdge, error) {
+	args := []any{conversationID, viewerID}
+	keyset := keysetBefore("m.created_at", "m.message_id", after, &args)
+	args = append(args, limit)
+	query := `SELECT ` + messageColumns + ` FROM messages m
+		WHERE m.conversation_id = $1
+			AND EXISTS (SELECT 1 FROM conversation_members cm WHERE cm.conversation_id = $1 AND cm.account_id = $2)
+			AND ` + notBlocked("m.sender_id", "$2::uuid") + keyset +
+		fmt.Sprintf(` ORDER BY m.created_at DESC, m.message_id DESC LIMIT $%d`, len(args))
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.MessageEdge{}
+	for rows.Next() {
+		m, cursor, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, &model.MessageEdge{Cursor: cursor.Encode(), Node: m})
+	}
+	return edges, rows.Err()
+}
+
+// Subscribe is served from the LISTEN connection, started on the first subscription.
+func (r *messageRepo) Subscribe(ctx context.Context, memberID string) (<-chan *model.Message, error) {
+	r.listener.start(r)
+	return r.listener.messages.Subscribe(ctx, memberID), nil
+}
+
+// recipients returns the members of conversationID who may see senderID's messages.
+func (r *messageRepo) recipients(ctx context.Context, conversationID, senderID string) ([]string, error) {
+	return r.queryIDs(ctx, `SELECT cm.account_id FROM conversation_members cm
+		WHERE cm.conversation_id = $1 AND `+notBlocked("cm.account_id", "$2::uuid"), conversationID, senderID)
+}
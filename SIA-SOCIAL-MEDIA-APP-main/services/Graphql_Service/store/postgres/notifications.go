@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type notificationRepo struct {
+	*conn
+	listener *notificationListener
+}
+
+// notificationColumns selects a notification joined with its triggering account, in scanNotification order.
+const notificationColumns = `
+		SELECT
+			n.notification_id, n.recipient_user_id, n.notification_type, n.entity_id, n.is_read, n.created_at,
+			n.triggering_user_id,
+			a.email, a.first_name, a.last_name, a.address, a.phone, a.age, a.gender, a.created_at, a.updated_at
+		FROM notifications n
+		LEFT JOIN accounts a ON n.triggering_user_id = a.id`
+
+func (r *notificationRepo) Create(ctx context.Context, n store.NewNotification) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	createdAt := n.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO notifications (recipient_user_id, triggering_user_id, notification_type, entity_id, is_read, created_at) VALUES ($1, $2, $3, $4, false, $5)`,
+		n.RecipientID, n.TriggeringUserID, notificationTypeColumn(n.Type), n.EntityID, createdAt)
+	if err != nil {
+		return fmt.Errorf("insert %s notification: %w", n.Type, err)
+	}
+	return nil
+}
+
+func (r *notificationRepo) GetByID(ctx context.Context, notificationID string) (*model.Notification, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	notif, _, err := scanNotification(r.db.QueryRowContext(ctx, notificationColumns+` WHERE n.notification_id = $1`, notificationID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return notif, nil
+}
+
+func (r *notificationRepo) ListForRecipient(ctx context.Context, recipientID string, filter store.NotificationFilter, after *store.Cursor, limit int) ([]*model.NotificationEdge, error) {
+	var queryBuilder strings.Builder
+	args := []any{recipientID}
+
+	// Base query selecting necessary fields and joining accounts for triggering user info
+	queryBuilder.WriteString(notificationColumns)
+	queryBuilder.WriteString(" WHERE n.recipient_user_id = $1")
+	queryBuilder.WriteString(notificationConditions(filter, &args))
+	queryBuilder.WriteString(keysetBefore("n.created_at", "n.notification_id", after, &args))
+	args = append(args, limit)
+	fmt.Fprintf(&queryBuilder, " ORDER BY n.created_at DESC, n.notification_id DESC LIMIT $%d", len(args))
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, queryBuilder.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.NotificationEdge{}
+	for rows.Next() {
+		notif, cursor, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, &model.NotificationEdge{Cursor: cursor.Encode(), Node: notif})
+	}
+	return edges, rows.Err()
+}
+
+func (r *notificationRepo) CountUnread(ctx context.Context, recipientID string) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications n WHERE n.recipient_user_id = $1 AND n.is_read = false AND `+actorNotHidden, recipientID).Scan(&n)
+	return n, err
+}
+
+// MarkRead compares IDs as text so a malformed ID simply matches nothing.
+func (r *notificationRepo) MarkRead(ctx context.Context, recipientID string, notificationIDs []string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET is_read = true WHERE recipient_user_id = $1 AND is_read = false AND notification_id::text = ANY($2)`,
+		recipientID, pq.Array(notificationIDs))
+	return err
+}
+
+func (r *notificationRepo) MarkAllRead(ctx context.Context, recipientID string, before time.Time) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET is_read = true WHERE recipient_user_id = $1 AND is_read = false AND created_at <= $2`,
+		recipientID, before)
+	return err
+}
+
+func (r *notificationRepo) ListGroups(ctx context.Context, recipientID string, filter store.NotificationFilter, window time.Duration, after *store.Cursor, limit int) ([]*store.NotificationGroup, error) {
+	args := []any{recipientID, int64(window / time.Second)}
+	var queryBuilder strings.Builder
+	// The most recent actors are sliced from an ordered array and deduplicated below, so a chatty actor
+	// can crowd out others in very large groups; 20 entries are plenty for three distinct names.
+	queryBuilder.WriteString(`
+		SELECT g.notification_type, g.entity_id, g.window_start, g.latest_at, g.notification_count, g.unread_count, g.actor_count, g.recent_actors
+		FROM (
+			SELECT
+				n.notification_type || '|' || COALESCE(w.entity_id::text, '') || '|' || w.window_start::text AS group_key,
+				n.notification_type, w.entity_id, w.window_start,
+				MAX(n.created_at) AS latest_at,
+				COUNT(*) AS notification_count,
+				COUNT(*) FILTER (WHERE NOT n.is_read) AS unread_count,
+				COUNT(DISTINCT n.triggering_user_id) AS actor_count,
+				(array_agg(n.triggering_user_id::text ORDER BY n.created_at DESC) FILTER (WHERE n.triggering_user_id IS NOT NULL))[1:20] AS recent_actors
+			FROM notifications n
+			CROSS JOIN LATERAL (SELECT
+				floor(extract(epoch FROM n.created_at) / $2::bigint)::bigint * $2::bigint AS window_start,
+				CASE WHEN n.notification_type IN ('new_follower', 'follow_request') THEN NULL ELSE n.entity_id END AS entity_id
+			) w
+			WHERE n.recipient_user_id = $1`)
+	queryBuilder.WriteString(notificationConditions(filter, &args))
+	queryBuilder.WriteString(`
+			GROUP BY n.notification_type, w.entity_id, w.window_start
+		) g
+		WHERE TRUE`)
+	// Group keys are text, so the cursor cannot go through keysetBefore and its uuid cast.
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		fmt.Fprintf(&queryBuilder, ` AND (g.latest_at, g.group_key COLLATE "C") < ($%d, $%d COLLATE "C")`, len(args)-1, len(args))
+	}
+	args = append(args, limit)
+	fmt.Fprintf(&queryBuilder, ` ORDER BY g.latest_at DESC, g.group_key COLLATE "C" DESC LIMIT $%d`, len(args))
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, queryBuilder.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*store.NotificationGroup{}
+	for rows.Next() {
+		var g store.NotificationGroup
+		var notificationType string
+		var entityID sql.NullString
+		var windowStart int64
+		var recentActors []string
+		if err := rows.Scan(&notificationType, &entityID, &windowStart, &g.LatestAt, &g.Count, &g.UnreadCount, &g.ActorCount, pq.Array(&recentActors)); err != nil {
+			return nil, err
+		}
+		g.Key = store.NotificationGroupKey{
+			Type:        model.NotificationType(strings.ToUpper(notificationType)),
+			EntityID:    entityID.String,
+			WindowStart: time.Unix(windowStart, 0).UTC(),
+		}
+		for _, id := range recentActors {
+			if len(g.ActorIDs) == store.NotificationGroupActors {
+				break
+			}
+			if !slices.Contains(g.ActorIDs, id) {
+				g.ActorIDs = append(g.ActorIDs, id)
+			}
+		}
+		groups = append(groups, &g)
+	}
+	return groups, rows.Err()
+}
+
+// Subscribe is served from the LISTEN connection, started on the first subscription.
+func (r *notificationRepo) Subscribe(ctx context.Context, recipientID string) (<-chan *model.Notification, error) {
+	r.listener.start(r)
+	return r.listener.notifications.Subscribe(ctx, recipientID), nil
+}
+
+func (r *notificationRepo) SubscribeUnreadCount(ctx context.Context, recipientID string) (<-chan int, error) {
+	r.listener.start(r)
+	return r.listener.unreadCounts.Subscribe(ctx, recipientID), nil
+}
+
+func scanNotification(row rowScanner) (*model.Notification, store.Cursor, error) {
+	var notif model.Notification
+	var notificationType string
+	var triggeringUserID, entityID sql.NullString
+	var createdAt time.Time
+	var accEmail, accFirstName, accLastName, accAddress, accPhone, accGender sql.NullString
+	var accAge sql.NullInt32
+	var accCreatedAt, accUpdatedAt sql.NullTime
+	err := row.Scan(
+		&notif.NotificationID, &notif.RecipientUserID, &notificationType, &entityID, &notif.IsRead, &createdAt,
+		&triggeringUserID,
+		&accEmail, &accFirstName, &accLastName, &accAddress, &accPhone, &accAge, &accGender, &accCreatedAt, &accUpdatedAt,
+	)
+	if err != nil {
+		return nil, store.Cursor{}, err
+	}
+	notif.NotificationType = model.NotificationType(strings.ToUpper(notificationType))
+	notif.CreatedAt = formatTime(createdAt)
+	notif.EntityID = nullString(entityID)
+	if triggeringUserID.Valid {
+		notif.TriggeringUser = &model.Account{
+			AccountID: triggeringUserID.String,
+			Email:     nullString(accEmail),
+			FirstName: accFirstName.String,
+			LastName:  accLastName.String,
+			Address:   nullString(accAddress),
+			Phone:     nullString(accPhone),
+			Age:       nullInt32(accAge),
+			Gender:    nullString(accGender),
+			UpdatedAt: formatNullTime(accUpdatedAt),
+		}
+		if accCreatedAt.Valid {
+			notif.TriggeringUser.CreatedAt = formatTime(accCreatedAt.Time)
+		}
+	}
+	return &notif, store.Cursor{CreatedAt: createdAt, ID: notif.NotificationID}, nil
+}
+
+// notificationTypeColumn returns the notification_type value stored for t: the enum name in lower case.
+func notificationTypeColumn(t model.NotificationType) string {
+	return strings.ToLower(string(t))
+}
+
+// actorNotHidden excludes notifications n caused by an account the recipient blocks, is blocked by or muted.
+// They are already suppressed when created; this also hides the ones from before the block or mute.
+var actorNotHidden = notBlocked("n.recipient_user_id", "n.triggering_user_id") + " AND " + notMuted("n.recipient_user_id", "n.triggering_user_id")
+
+// notificationConditions returns the WHERE conditions, each starting with " AND", for filter on notifications n.
+// Notifications from hidden actors (see actorNotHidden) never match.
+func notificationConditions(filter store.NotificationFilter, args *[]any) string {
+	var b strings.Builder
+	b.WriteString(" AND " + actorNotHidden)
+	if filter.IsRead != nil {
+		*args = append(*args, *filter.IsRead)
+		fmt.Fprintf(&b, " AND n.is_read = $%d", len(*args))
+	}
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			types[i] = notificationTypeColumn(t)
+		}
+		*args = append(*args, pq.Array(types))
+		fmt.Fprintf(&b, " AND n.notification_type = ANY($%d)", len(*args))
+	}
+	if filter.EntityID != "" {
+		*args = append(*args, filter.EntityID)
+		fmt.Fprintf(&b, " AND n.entity_id::text = $%d", len(*args))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		*args = append(*args, filter.CreatedAfter)
+		fmt.Fprintf(&b, " AND n.created_at >= $%d", len(*args))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		*args = append(*args, filter.CreatedBefore)
+		fmt.Fprintf(&b, " AND n.created_at < $%d", len(*args))
+	}
+	return b.String()
+}
@@ -0,0 +1,154 @@
+// Package postgres implements the store repositories on top of a shared *sql.DB pool.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graphql/config"
+	"graphql/store"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Open creates the shared connection pool. It is called once from server.go.
+func Open(cfg config.DBConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %v", err)
+	}
+
+	return db, nil
+}
+
+// New returns Postgres-backed repositories sharing db. Every statement is bounded by cfg.StatementTimeout;
+// notification and message subscriptions each open one extra connection to cfg.URL for LISTEN.
+func New(db *sql.DB, cfg config.DBConfig) store.Repositories {
+	c := &conn{db: db, timeout: cfg.StatementTimeout}
+	return store.Repositories{
+		Accounts:                &accountRepo{c},
+		Posts:                   &postRepo{c},
+		Follows:                 &followRepo{c},
+		FollowRequests:          &followRequestRepo{c},
+		Blocks:                  &blockRepo{c},
+		Timelines:               &timelineRepo{c},
+		Notifications:           &notificationRepo{conn: c, listener: newNotificationListener(cfg.URL)},
+		NotificationPreferences: &notificationPreferenceRepo{c},
+		Profiles:                &profileRepo{c},
+		RefreshTokens:           &refreshTokenRepo{c},
+		Roles:                   &roleRepo{c},
+		Comments:                &commentRepo{c},
+		Reactions:               &reactionRepo{c},
+		Messages:                &messageRepo{conn: c, listener: newMessageListener(cfg.URL)},
+	}
+}
+
+// conn is the state shared by every repository.
+type conn struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// withTimeout bounds a single statement by the configured statement timeout.
+func (c *conn) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// queryIDs runs a query returning a single ID column.
+func (c *conn) queryIDs(ctx context.Context, query string, args ...any) ([]string, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation (23505).
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// violatedConstraint returns the name of the constraint a Postgres error refers to, if any.
+func violatedConstraint(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	return ""
+}
+
+// notFound maps sql.ErrNoRows to store.ErrNotFound and leaves other errors untouched.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+	return err
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func formatNullTime(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := formatTime(t.Time)
+	return &s
+}
+
+// nullUUID binds an optional account ID, such as an anonymous viewer's "", as NULL.
+func nullUUID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullInt32(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+// keysetBefore returns the condition restricting a newest-first listing to rows after cursor, or "" for
+// the first page. The cursor's two values are appended to args.
+func keysetBefore(createdAtColumn, idColumn string, after *store.Cursor, args *[]any) string {
+	if after == nil {
+		return ""
+	}
+	*args = append(*args, after.CreatedAt, after.ID)
+	return fmt.Sprintf(" AND (%s, %s) < ($%d, $%d::uuid)", createdAtColumn, idColumn, len(*args)-1, len(*args))
+}
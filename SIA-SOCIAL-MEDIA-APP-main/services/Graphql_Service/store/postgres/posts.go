@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"time"
+)
+
+// postColumns selects a post (alias p).
+const postColumns = `p.post_id, p.title, p.content, p.author_id, p.created_at, p.updated_at, p.deleted_at`
+
+type postRepo struct{ *conn }
+
+func scanPost(row rowScanner) (*model.Post, store.Cursor, error) {
+	var post model.Post
+	var createdAt time.Time
+	var updatedAt, deletedAt sql.NullTime
+	if err := row.Scan(&post.PostID, &post.Title, &post.Content, &post.AuthorID, &createdAt, &updatedAt, &deletedAt); err != nil {
+		return nil, store.Cursor{}, err
+	}
+	post.CreatedAt = formatTime(createdAt)
+	post.UpdatedAt = formatNullTime(updatedAt)
+	post.DeletedAt = formatNullTime(deletedAt)
+	return &post, store.Cursor{CreatedAt: createdAt, ID: post.PostID}, nil
+}
+
+// queryPost runs a statement returning one row of postColumns.
+func (r *postRepo) queryPost(ctx context.Context, query string, args ...any) (*model.Post, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	post, _, err := scanPost(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return post, nil
+}
+
+func (r *postRepo) queryPosts(ctx context.Context, query string, args ...any) ([]*model.PostEdge, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := []*model.PostEdge{}
+	for rows.Next() {
+		post, cursor, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, &model.PostEdge{Cursor: cursor.Encode(), Node: post})
+	}
+	return edges, rows.Err()
+}
+
+func (r *postRepo) Create(ctx context.Context, input store.NewPost) (*model.Post, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var postID string
+	var createdAt time.Time
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO posts (title, content, author_id, created_at) VALUES ($1, $2, $3, NOW()) RETURNING post_id, created_at`,
+		input.Title, input.Content, input.AuthorID).Scan(&postID, &createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert post: %w", err)
+	}
+	return &model.Post{PostID: postID, Title: input.Title, Content: input.Content, AuthorID: input.AuthorID, CreatedAt: formatTime(createdAt)}, nil
+}
+
+func (r *postRepo) GetByID(ctx context.Context, postID string) (*model.Post, error) {
+	return r.queryPost(ctx, `SELECT `+postColumns+` FROM posts p WHERE p.post_id = $1 AND p.deleted_at IS NULL`, postID)
+}
+
+func (r *postRepo) GetVisible(ctx context.Context, postID, viewerID string) (*model.Post, error) {
+	return r.queryPost(ctx, `SELECT `+postColumns+` FROM posts p
+		WHERE p.post_id = $1 AND p.deleted_at IS NULL AND `+visibleTo("p.author_id", "$2::uuid"), postID, nullUUID(viewerID))
+}
+
+func (r *postRepo) ListRecent(ctx context.Context, viewerID string, after *store.Cursor, limit int) ([]*model.PostEdge, error) {
+	args := []any{nullUUID(viewerID)}
+	keyset := keysetBefore("p.created_at", "p.post_id", after, &args)
+	args = append(args, limit)
+	return r.queryPosts(ctx, `SELECT `+postColumns+` FROM posts p
+		WHERE p.deleted_at IS NULL AND `+visibleTo("p.author_id", "$1::uuid")+` AND `+notMuted("$1::uuid", "p.author_id")+keyset+fmt.Sprintf(` ORDER BY p.created_at DESC, p.post_id DESC LIMIT $%d`, len(args)),
+		args...)
+}
+
+// visibleTo returns a condition that holds when the posts of the author in authorColumn may be shown to the
+// viewer ID in viewerParam: neither blocks the other, and the author is public, is the viewer, or is followed
+// by the viewer. viewerParam is a uuid parameter such as "$1::uuid", bound with nullUUID so an anonymous
+// viewer is NULL and matches nothing.
+func visibleTo(authorColumn, viewerParam string) string {
+	return fmt.Sprintf(`%[3]s AND (NOT EXISTS (SELECT 1 FROM accounts va WHERE va.id = %[1]s AND va.is_private)
+		OR %[1]s = %[2]s
+		OR EXISTS (SELECT 1 FROM follows vf WHERE vf.followed_user_id = %[1]s AND vf.follower_user_id = %[2]s))`,
+		authorColumn, viewerParam, notBlocked(authorColumn, viewerParam))
+}
+
+func (r *postRepo) Update(ctx context.Context, postID, authorID string, update store.PostUpdate) (*model.Post, error) {
+	// Saving the revision and applying the edit in one statement keeps them atomic; FOR UPDATE makes
+	// concurrent edits of the same post queue up so every replaced version is recorded.
+	return r.queryPost(ctx, `
+		WITH prev AS (
+			SELECT post_id, title, content, COALESCE(updated_at, created_at) AS written_at
+			FROM posts WHERE post_id = $1 AND author_id = $2 AND deleted_at IS NULL
+			FOR UPDATE
+		), revision AS (
+			INSERT INTO post_revisions (post_id, title, content, created_at)
+			SELECT post_id, title, content, written_at FROM prev
+		), p AS (
+			UPDATE posts SET title = COALESCE($3, title), content = COALESCE($4, content), updated_at = NOW()
+			WHERE post_id = (SELECT post_id FROM prev)
+			RETURNING *
+		)
+		SELECT `+postColumns+` FROM p`,
+		postID, authorID, update.Title, update.Content)
+}
+
+func (r *postRepo) Delete(ctx context.Context, postID, authorID string) (*model.Post, error) {
+	return r.queryPost(ctx, `
+		WITH p AS (
+			UPDATE posts SET deleted_at = NOW()
+			WHERE post_id = $1 AND author_id = $2 AND deleted_at IS NULL
+			RETURNING *
+		), unlisted AS (
+			DELETE FROM timelines WHERE post_id = (SELECT post_id FROM p)
+		)
+		SELECT `+postColumns+` FROM p`,
+		postID, authorID)
+}
+
+func (r *postRepo) ListRevisions(ctx context.Context, postID string) ([]*model.PostRevision, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT revision_id, title, content, created_at, replaced_at FROM post_revisions
+		WHERE post_id = $1 ORDER BY replaced_at DESC, revision_id`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*model.PostRevision{}
+	for rows.Next() {
+		var rev model.PostRevision
+		var createdAt, replacedAt time.Time
+		if err := rows.Scan(&rev.RevisionID, &rev.Title, &rev.Content, &createdAt, &replacedAt); err != nil {
+			return nil, err
+		}
+		rev.CreatedAt = formatTime(createdAt)
+		rev.ReplacedAt = formatTime(replacedAt)
+		revisions = append(revisions, &rev)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type notificationPreferenceRepo struct{ *conn }
+
+func (r *notificationPreferenceRepo) Get(ctx context.Context, accountID string) (*store.NotificationPreferences, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT notification_type, in_app, email, webhook FROM notification_preferences WHERE account_id = $1`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := &store.NotificationPreferences{Types: map[model.NotificationType]store.NotificationChannels{}}
+	for rows.Next() {
+		var notificationType string
+		var ch store.NotificationChannels
+		if err := rows.Scan(&notificationType, &ch.InApp, &ch.Email, &ch.Webhook); err != nil {
+			return nil, err
+		}
+		prefs.Types[model.NotificationType(strings.ToUpper(notificationType))] = ch
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRowContext(ctx,
+		`SELECT COALESCE((SELECT webhook_url FROM notification_settings WHERE account_id = $1), '')`, accountID).Scan(&prefs.WebhookURL)
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (r *notificationPreferenceRepo) Update(ctx context.Context, accountID string, update store.NotificationPreferencesUpdate) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for t, ch := range update.Types {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_preferences (account_id, notification_type, in_app, email, webhook) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (account_id, notification_type) DO UPDATE SET in_app = $3, email = $4, webhook = $5`,
+			accountID, notificationTypeColumn(t), ch.InApp, ch.Email, ch.Webhook)
+		if err != nil {
+			return fmt.Errorf("upsert %s preference: %w", t, err)
+		}
+	}
+	if update.WebhookURL != nil {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_settings (account_id, webhook_url) VALUES ($1, NULLIF($2, ''))
+			ON CONFLICT (account_id) DO UPDATE SET webhook_url = NULLIF($2, '')`,
+			accountID, *update.WebhookURL)
+		if err != nil {
+			return fmt.Errorf("upsert notification settings: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *notificationPreferenceRepo) Deliveries(ctx context.Context, t model.NotificationType, actorID string, recipientIDs []string) (map[string]store.NotificationDelivery, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	def := store.DefaultNotificationChannels
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT a.id, COALESCE(p.in_app, $4), COALESCE(p.email, $5), COALESCE(p.webhook, $6), COALESCE(a.email, ''), COALESCE(s.webhook_url, '')
+		FROM accounts a
+		LEFT JOIN notification_preferences p ON p.account_id = a.id AND p.notification_type = $2
+		LEFT JOIN notification_settings s ON s.account_id = a.id
+		WHERE a.id = ANY($1::uuid[])
+		  AND NOT ($2 = 'new_post' AND EXISTS (
+			SELECT 1 FROM notification_mutes m WHERE m.account_id = a.id AND m.muted_account_id = $3::uuid))
+		  AND `+notBlocked("a.id", "$3::uuid")+` AND `+notMuted("a.id", "$3::uuid"),
+		pq.Array(recipientIDs), notificationTypeColumn(t), actorID, def.InApp, def.Email, def.Webhook)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := map[string]store.NotificationDelivery{}
+	for rows.Next() {
+		var id string
+		var d store.NotificationDelivery
+		if err := rows.Scan(&id, &d.InApp, &d.NotificationChannels.Email, &d.Webhook, &d.Email, &d.WebhookURL); err != nil {
+			return nil, err
+		}
+		d.Webhook = d.Webhook && d.WebhookURL != ""
+		if d.InApp || d.NotificationChannels.Email || d.Webhook {
+			deliveries[id] = d
+		}
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *notificationPreferenceRepo) MuteNewPosts(ctx context.Context, accountID, authorID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO notification_mutes (account_id, muted_account_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, accountID, authorID)
+	if err != nil {
+		return false, fmt.Errorf("insert notification mute: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *notificationPreferenceRepo) UnmuteNewPosts(ctx context.Context, accountID, authorID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM notification_mutes WHERE account_id = $1 AND muted_account_id = $2`, accountID, authorID)
+	if err != nil {
+		return false, fmt.Errorf("delete notification mute: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *notificationPreferenceRepo) MutedNewPostAuthors(ctx context.Context, accountID string) ([]string, error) {
+	return r.queryIDs(ctx,
+		`SELECT muted_account_id FROM notification_mutes WHERE account_id = $1 ORDER BY created_at DESC, muted_account_id DESC`, accountID)
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+	"strings"
+)
+
+// profileColumns is the column list scanned by scanProfile.
+const profileColumns = `profile_id, username, email, first_name, middle_name, last_name, bio,
+	profile_picture_url, banner_picture_url, to_char(date_of_birth, 'YYYY-MM-DD'), address`
+
+type profileRepo struct{ *conn }
+
+func scanProfile(row rowScanner) (*model.Profile, error) {
+	var p model.Profile
+	var dateOfBirth sql.NullString
+	err := row.Scan(&p.ProfileID, &p.Username, &p.Email, &p.FirstName, &p.MiddleName, &p.LastName, &p.Bio,
+		&p.ProfilePictureURL, &p.BannerPictureURL, &dateOfBirth, &p.Address)
+	if err != nil {
+		return nil, err
+	}
+	p.DateOfBirth = nullString(dateOfBirth)
+	return &p, nil
+}
+
+func (r *profileRepo) Create(ctx context.Context, input store.NewProfile) (*model.Profile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO profiles (profile_id, username, email, first_name, middle_name, last_name, bio,
+			profile_picture_url, banner_picture_url, date_of_birth, address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING `+profileColumns,
+		input.ProfileID, input.Username, input.Email, input.FirstName, input.MiddleName, input.LastName, input.Bio,
+		input.ProfilePictureURL, input.BannerPictureURL, input.DateOfBirth, input.Address)
+	p, err := scanProfile(row)
+	if err != nil {
+		if conflict := profileConflict(err); conflict != nil {
+			return nil, conflict
+		}
+		return nil, fmt.Errorf("insert profile: %w", err)
+	}
+	return p, nil
+}
+
+func (r *profileRepo) Update(ctx context.Context, profileID string, update store.ProfileUpdate) (*model.Profile, error) {
+	var sets []string
+	args := []any{profileID}
+	// set appends "column = value"; an empty value becomes NULL, cast back to the column's type.
+	set := func(column string, value *string, sqlType string) {
+		if value == nil {
+			return
+		}
+		args = append(args, *value)
+		sets = append(sets, fmt.Sprintf("%s = NULLIF($%d::text, '')::%s", column, len(args), sqlType))
+	}
+	if update.Username != nil {
+		args = append(args, *update.Username)
+		sets = append(sets, fmt.Sprintf("username = $%d", len(args)))
+	}
+	set("middle_name", update.MiddleName, "varchar")
+	set("bio", update.Bio, "text")
+	set("profile_picture_url", update.ProfilePictureURL, "varchar")
+	set("banner_picture_url", update.BannerPictureURL, "varchar")
+	set("date_of_birth", update.DateOfBirth, "date")
+	set("address", update.Address, "varchar")
+	if len(sets) == 0 {
+		return r.GetByID(ctx, profileID)
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	query := `UPDATE profiles SET ` + strings.Join(sets, ", ") + ` WHERE profile_id = $1 RETURNING ` + profileColumns
+	p, err := scanProfile(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if conflict := profileConflict(err); conflict != nil {
+			return nil, conflict
+		}
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+// profileConflict maps unique violations on profiles to the matching store error, or returns nil.
+func profileConflict(err error) error {
+	if !isUniqueViolation(err) {
+		return nil
+	}
+	if violatedConstraint(err) == "profiles_username_key" {
+		return store.ErrUsernameTaken
+	}
+	return store.ErrConflict
+}
+
+func (r *profileRepo) GetByID(ctx context.Context, profileID string) (*model.Profile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	p, err := scanProfile(r.db.QueryRowContext(ctx, `SELECT `+profileColumns+` FROM profiles WHERE profile_id = $1`, profileID))
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return p, nil
+}
+
+func (r *profileRepo) List(ctx context.Context, viewerID string) ([]*model.Profile, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `SELECT `+profileColumns+` FROM profiles
+		WHERE `+notBlocked("profile_id", "$1::uuid")+` ORDER BY username`, nullUUID(viewerID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []*model.Profile{}
+	for rows.Next() {
+		p, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"graphql/store"
+
+	"github.com/lib/pq"
+)
+
+type reactionRepo struct{ *conn }
+
+func (r *reactionRepo) Set(ctx context.Context, target store.ReactionTarget, accountID, reaction string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Insert first: a concurrent first reaction by the same account waits on the conflict and then takes
+	// the update path below, so the counters never count one account twice.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO reactions (target_kind, target_id, account_id, reaction) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (target_kind, target_id, account_id) DO NOTHING`,
+		target.Kind, target.ID, accountID, reaction)
+	if err != nil {
+		return false, fmt.Errorf("insert reaction: %w", err)
+	}
+	created := false
+	if n, _ := result.RowsAffected(); n > 0 {
+		created = true
+	} else {
+		var previous string
+		err := tx.QueryRowContext(ctx, `
+			SELECT reaction FROM reactions WHERE target_kind = $1 AND target_id = $2 AND account_id = $3 FOR UPDATE`,
+			target.Kind, target.ID, accountID).Scan(&previous)
+		if err != nil {
+			return false, fmt.Errorf("lock reaction: %w", err)
+		}
+		if previous == reaction {
+			return false, tx.Commit()
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE reactions SET reaction = $4, created_at = NOW() WHERE target_kind = $1 AND target_id = $2 AND account_id = $3`,
+			target.Kind, target.ID, accountID, reaction); err != nil {
+			return false, fmt.Errorf("update reaction: %w", err)
+		}
+		if err := adjustReactionCount(ctx, tx, target, previous, -1); err != nil {
+			return false, err
+		}
+	}
+	if err := adjustReactionCount(ctx, tx, target, reaction, 1); err != nil {
+		return false, err
+	}
+	return created, tx.Commit()
+}
+
+func (r *reactionRepo) Remove(ctx context.Context, target store.ReactionTarget, accountID string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var previous string
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM reactions WHERE target_kind = $1 AND target_id = $2 AND account_id = $3 RETURNING reaction`,
+		target.Kind, target.ID, accountID).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("delete reaction: %w", err)
+	}
+	if err := adjustReactionCount(ctx, tx, target, previous, -1); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// adjustReactionCount adds delta to the counter of one reaction on target.
+func adjustReactionCount(ctx context.Context, tx *sql.Tx, target store.ReactionTarget, reaction string, delta int) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO reaction_counts (target_kind, target_id, reaction, count) VALUES ($1, $2, $3, GREATEST($4, 0))
+		ON CONFLICT (target_kind, target_id, reaction) DO UPDATE SET count = GREATEST(reaction_counts.count + $4, 0)`,
+		target.Kind, target.ID, reaction, delta)
+	if err != nil {
+		return fmt.Errorf("update reaction count: %w", err)
+	}
+	return nil
+}
+
+func (r *reactionRepo) Counts(ctx context.Context, kind string, ids []string) (map[string]map[string]int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT target_id, reaction, count FROM reaction_counts
+		WHERE target_kind = $1 AND target_id = ANY($2::uuid[]) AND count > 0`,
+		kind, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]map[string]int{}
+	for rows.Next() {
+		var id, reaction string
+		var n int
+		if err := rows.Scan(&id, &reaction, &n); err != nil {
+			return nil, err
+		}
+		if counts[id] == nil {
+			counts[id] = map[string]int{}
+		}
+		counts[id][reaction] = n
+	}
+	return counts, rows.Err()
+}
+
+func (r *reactionRepo) ForAccount(ctx context.Context, kind string, ids []string, accountID string) (map[string]string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT target_id, reaction FROM reactions
+		WHERE target_kind = $1 AND target_id = ANY($2::uuid[]) AND account_id = $3`,
+		kind, pq.Array(ids), accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := map[string]string{}
+	for rows.Next() {
+		var id, reaction string
+		if err := rows.Scan(&id, &reaction); err != nil {
+			return nil, err
+		}
+		reactions[id] = reaction
+	}
+	return reactions, rows.Err()
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"graphql/store"
+	"time"
+)
+
+type refreshTokenRepo struct{ *conn }
+
+func (r *refreshTokenRepo) Create(ctx context.Context, t store.NewRefreshToken) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (account_id, token_digest, expires_at)
+		VALUES ($1, $2, $3)`,
+		t.AccountID, t.Digest, t.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("insert refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r *refreshTokenRepo) Rotate(ctx context.Context, oldDigest, newDigest string, expiresAt time.Time) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	// A concurrent rotation of the same token blocks on the row lock and then sees revoked_at set,
+	// so only one caller ever gets a successor token.
+	var accountID string
+	err := r.db.QueryRowContext(ctx, `
+		WITH revoked AS (
+			UPDATE refresh_tokens SET revoked_at = NOW()
+			WHERE token_digest = $1 AND revoked_at IS NULL AND expires_at > NOW()
+			RETURNING account_id
+		)
+		INSERT INTO refresh_tokens (account_id, token_digest, expires_at)
+		SELECT account_id, $2, $3 FROM revoked
+		RETURNING account_id`,
+		oldDigest, newDigest, expiresAt).Scan(&accountID)
+	if err != nil {
+		return "", notFound(err)
+	}
+	return accountID, nil
+}
+
+func (r *refreshTokenRepo) Revoke(ctx context.Context, digest string) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	var accountID string
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE token_digest = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING account_id`, digest).Scan(&accountID)
+	if err != nil {
+		return "", notFound(err)
+	}
+	return accountID, nil
+}
+
+func (r *refreshTokenRepo) RevokeAll(ctx context.Context, accountID string) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE account_id = $1 AND revoked_at IS NULL AND expires_at > NOW()`, accountID)
+	if err != nil {
+		return 0, fmt.Errorf("revoke refresh tokens: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type roleRepo struct{ *conn }
+
+func (r *roleRepo) ListForAccount(ctx context.Context, accountID string) ([]string, error) {
+	return r.queryIDs(ctx, `SELECT role FROM account_roles WHERE account_id = $1 ORDER BY role`, accountID)
+}
+
+func (r *roleRepo) Grant(ctx context.Context, accountID, role string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO account_roles (account_id, role) VALUES ($1, $2)
+		ON CONFLICT (account_id, role) DO NOTHING`, accountID, strings.ToLower(role))
+	if err != nil {
+		return false, fmt.Errorf("grant role: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *roleRepo) Revoke(ctx context.Context, accountID, role string) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, `DELETE FROM account_roles WHERE account_id = $1 AND role = $2`, accountID, strings.ToLower(role))
+	if err != nil {
+		return false, fmt.Errorf("revoke role: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"graphql/graph/model"
+	"graphql/store"
+)
+
+type timelineRepo struct{ *conn }
+
+func (r *timelineRepo) FanOut(ctx context.Context, postID string, maxFollowers int) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO timelines (owner_id, post_id, author_id, created_at)
+		SELECT f.follower_user_id, p.post_id, p.author_id, p.created_at
+		FROM posts p
+		JOIN accounts a ON a.id = p.author_id AND a.follower_count <= $2
+		JOIN follows f ON f.followed_user_id = p.author_id
+		WHERE p.post_id = $1 AND p.deleted_at IS NULL
+		ON CONFLICT DO NOTHING`,
+		postID, maxFollowers)
+	if err != nil {
+		return 0, fmt.Errorf("fan out post: %w", err)
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+func (r *timelineRepo) Backfill(ctx context.Context, ownerID, authorID string, maxFollowers, limit int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO timelines (owner_id, post_id, author_id, created_at)
+		SELECT $1, p.post_id, p.author_id, p.created_at
+		FROM posts p
+		JOIN accounts a ON a.id = p.author_id AND a.follower_count <= $3
+		WHERE p.author_id = $2 AND p.deleted_at IS NULL
+		ORDER BY p.created_at DESC, p.post_id DESC
+		LIMIT $4
+		ON CONFLICT DO NOTHING`,
+		ownerID, authorID, maxFollowers, limit)
+	if err != nil {
+		return fmt.Errorf("backfill timeline: %w", err)
+	}
+	return nil
+}
+
+func (r *timelineRepo) Prune(ctx context.Context, ownerID, authorID string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM timelines WHERE owner_id = $1 AND author_id = $2`, ownerID, authorID); err != nil {
+		return fmt.Errorf("prune timeline: %w", err)
+	}
+	return nil
+}
+
+func (r *timelineRepo) List(ctx context.Context, ownerID string, maxFollowers int, after *store.Cursor, limit int) ([]*model.PostEdge, error) {
+	args := []any{ownerID, maxFollowers}
+	timelineKeyset := keysetBefore("t.created_at", "t.post_id", after, &args)
+	postKeyset := keysetBefore("p.created_at", "p.post_id", after, &args)
+	args = append(args, limit)
+	limitParam := fmt.Sprintf("$%d", len(args))
+
+	// Both branches are index range scans that stop after one page: the owner's timeline, and the recent
+	// posts of the (usually zero) followed authors too big to fan out.
+	return (&postRepo{r.conn}).queryPosts(ctx, `
+		SELECT `+postColumns+` FROM (
+			(SELECT t.post_id FROM timelines t
+			WHERE t.owner_id = $1 AND `+notMuted("$1::uuid", "t.author_id")+timelineKeyset+`
+			ORDER BY t.created_at DESC, t.post_id DESC LIMIT `+limitParam+`)
+			UNION
+			(SELECT p.post_id FROM follows f
+			JOIN accounts a ON a.id = f.followed_user_id AND a.follower_count > $2
+			JOIN posts p ON p.author_id = f.followed_user_id AND p.deleted_at IS NULL
+			WHERE f.follower_user_id = $1 AND `+notMuted("$1::uuid", "f.followed_user_id")+postKeyset+`
+			ORDER BY p.created_at DESC, p.post_id DESC LIMIT `+limitParam+`)
+		) feed
+		JOIN posts p ON p.post_id = feed.post_id
+		WHERE p.deleted_at IS NULL AND `+visibleTo("p.author_id", "$1::uuid")+`
+		ORDER BY p.created_at DESC, p.post_id DESC LIMIT `+limitParam,
+		args...)
+}
@@ -0,0 +1,542 @@
+// Package store defines the persistence interfaces used by the GraphQL resolvers.
+//
+// Two implementations exist: store/postgres for the running service and store/memory for
+// resolver tests that should not need a database.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"graphql/graph/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist.
+	ErrNotFound = errors.New("store: not found")
+	// ErrConflict is returned when a write violates a uniqueness constraint.
+	ErrConflict = errors.New("store: conflict")
+	// ErrUsernameTaken is the ErrConflict returned when a profile username is already in use.
+	ErrUsernameTaken = fmt.Errorf("%w: username already taken", ErrConflict)
+)
+
+// Repositories bundles every repository the resolvers depend on.
+type Repositories struct {
+	Accounts       AccountRepository
+	Posts          PostRepository
+	Follows        FollowRepository
+	FollowRequests FollowRequestRepository
+	Blocks         BlockRepository
+	Messages       MessageRepository
+	Timelines      TimelineRepository
+	Notifications  NotificationRepository
+	// NotificationPreferences decides which notifications are created, and over which channels they go out.
+	NotificationPreferences NotificationPreferenceRepository
+	Profiles                ProfileRepository
+	RefreshTokens           RefreshTokenRepository
+	Roles                   RoleRepository
+	Comments                CommentRepository
+	Reactions               ReactionRepository
+}
+
+// NewAccount carries the columns written when registering an account.
+type NewAccount struct {
+	Email        string
+	PasswordHash string
+	FirstName    string
+	LastName     string
+	Address      *string
+	Phone        *string
+	Age          int32
+	Gender       *string
+}
+
+type AccountRepository interface {
+	Create(ctx context.Context, input NewAccount) (*model.Account, error)
+	// GetByID returns ErrNotFound when no account has the given ID.
+	GetByID(ctx context.Context, accountID string) (*model.Account, error)
+	// GetByIDs returns the accounts with the given IDs, keyed by ID. Unknown IDs are absent from the result.
+	GetByIDs(ctx context.Context, accountIDs []string) (map[string]*model.Account, error)
+	// List returns accounts newest first, starting after the given cursor (nil for the first page).
+	List(ctx context.Context, after *Cursor, limit int) ([]*model.AccountEdge, error)
+	// GetCredentials returns the ID and stored password hash of the account registered with email,
+	// or ErrNotFound.
+	GetCredentials(ctx context.Context, email string) (accountID string, passwordHash string, err error)
+	UpdatePasswordHash(ctx context.Context, accountID, passwordHash string) error
+	// SetPrivate changes whether new followers of accountID need approval, or returns ErrNotFound.
+	SetPrivate(ctx context.Context, accountID string, private bool) (*model.Account, error)
+}
+
+// NewPost carries the columns written when creating a post.
+type NewPost struct {
+	Title    string
+	Content  string
+	AuthorID string
+}
+
+// PostUpdate lists the post fields to change. Nil fields are left untouched.
+type PostUpdate struct {
+	Title   *string
+	Content *string
+}
+
+// PostRepository returns posts without Author; the Post.author resolver loads it by AuthorID.
+// Soft-deleted posts are never returned by the read methods.
+type PostRepository interface {
+	Create(ctx context.Context, input NewPost) (*model.Post, error)
+	// GetByID returns ErrNotFound when no live post has the given ID. It does not check visibility.
+	GetByID(ctx context.Context, postID string) (*model.Post, error)
+	// GetVisible is GetByID for posts shown to viewerID ("" for anonymous viewers): it also returns ErrNotFound
+	// when the author and viewerID block each other in either direction, or the author is private and viewerID
+	// is neither the author nor one of their followers.
+	GetVisible(ctx context.Context, postID, viewerID string) (*model.Post, error)
+	// ListRecent returns the posts visible to viewerID (as in GetVisible) newest first, starting after the given
+	// cursor (nil for the first page). Posts of accounts viewerID muted are left out.
+	ListRecent(ctx context.Context, viewerID string, after *Cursor, limit int) ([]*model.PostEdge, error)
+	// Update saves the current version of a live post written by authorID as a revision, then applies update.
+	// It returns ErrNotFound when there is no such post.
+	Update(ctx context.Context, postID, authorID string, update PostUpdate) (*model.Post, error)
+	// Delete soft-deletes a live post written by authorID and returns it with DeletedAt set, or ErrNotFound.
+	// The post is also removed from every timeline.
+	Delete(ctx context.Context, postID, authorID string) (*model.Post, error)
+	// ListRevisions returns the replaced versions of a post, newest first.
+	ListRevisions(ctx context.Context, postID string) ([]*model.PostRevision, error)
+}
+
+// FollowCounts are an account's maintained follow counters.
+type FollowCounts struct {
+	Followers int
+	Following int
+}
+
+// SuggestionMutuals is how many mutual followers a Suggestion lists.
+const SuggestionMutuals = 3
+
+// Suggestion is an account to follow found through the accounts a viewer already follows.
+type Suggestion struct {
+	AccountID string
+	// MutualCount is how many accounts the viewer follows also follow AccountID.
+	MutualCount int
+	// MutualIDs holds up to SuggestionMutuals of those accounts, most recent follow of AccountID first.
+	MutualIDs []string
+}
+
+// FollowRepository also maintains each account's follower and following counts. TimelineRepository uses
+// the follower count to pick between fan-out on write and on read.
+type FollowRepository interface {
+	// Follow reports whether a new follow row was created (false if it already existed).
+	Follow(ctx context.Context, followerID, followedID string) (bool, error)
+	// Unfollow reports whether a follow row was removed.
+	Unfollow(ctx context.Context, followerID, followedID string) (bool, error)
+	IsFollowing(ctx context.Context, followerID, followedID string) (bool, error)
+	// FollowedAmong returns which of accountIDs followerID follows. Accounts it does not follow are absent.
+	FollowedAmong(ctx context.Context, followerID string, accountIDs []string) (map[string]bool, error)
+	// FollowersAmong returns which of accountIDs follow followedID. Accounts that do not are absent.
+	FollowersAmong(ctx context.Context, followedID string, accountIDs []string) (map[string]bool, error)
+	FollowerIDs(ctx context.Context, accountID string) ([]string, error)
+	FollowingIDs(ctx context.Context, accountID string) ([]string, error)
+	// Followers returns the accounts following accountID, most recent follow first, starting after the given
+	// cursor (nil for the first page). Cursors are positions in the follow list, not account cursors.
+	Followers(ctx context.Context, accountID string, after *Cursor, limit int) ([]*model.AccountEdge, error)
+	// Following returns the accounts accountID follows, ordered and paged like Followers.
+	Following(ctx context.Context, accountID string, after *Cursor, limit int) ([]*model.AccountEdge, error)
+	// Counts returns the counters of the given accounts, keyed by ID. Unknown IDs are absent.
+	Counts(ctx context.Context, accountIDs []string) (map[string]FollowCounts, error)
+	// Suggest returns up to limit friends of friends of viewerID: accounts followed by the accounts viewerID
+	// follows, ranked by MutualCount, then by ID. viewerID itself and accounts it follows, asked to follow,
+	// muted, blocks or is blocked by are left out.
+	Suggest(ctx context.Context, viewerID string, limit int) ([]Suggestion, error)
+}
+
+// FollowRequestRepository holds pending requests to follow private accounts.
+type FollowRequestRepository interface {
+	// Create reports whether a new request was stored (false if one was already pending).
+	Create(ctx context.Context, requesterID, targetID string) (bool, error)
+	// Delete drops a pending request without approving it. It reports whether one was pending.
+	Delete(ctx context.Context, requesterID, targetID string) (bool, error)
+	// Approve turns a pending request into a follow, maintaining the counts like FollowRepository.Follow.
+	// It reports whether that started a follow: false if no request was pending or the requester already follows.
+	Approve(ctx context.Context, requesterID, targetID string) (bool, error)
+	// ApproveAll approves every request pending for targetID and returns the requesters who started following it.
+	ApproveAll(ctx context.Context, targetID string) ([]string, error)
+	// ListPending returns the requests pending for targetID, newest first, starting after the given cursor
+	// (nil for the first page).
+	ListPending(ctx context.Context, targetID string, after *Cursor, limit int) ([]*model.FollowRequestEdge, error)
+	// RequestedAmong returns which of targetIDs requesterID has pending requests to. Others are absent.
+	RequestedAmong(ctx context.Context, requesterID string, targetIDs []string) (map[string]bool, error)
+}
+
+// BlockRepository records blocks and mutes between accounts. A block cuts both accounts off from each other:
+// neither sees the other's posts or gets notifications caused by the other. A mute only hides the muted
+// account's posts from the muter's feeds and suppresses its notifications; the muted account cannot tell.
+type BlockRepository interface {
+	// Block reports whether a new block was stored. It does not touch follows; callers remove those.
+	Block(ctx context.Context, blockerID, blockedID string) (bool, error)
+	// Unblock reports whether a block was removed.
+	Unblock(ctx context.Context, blockerID, blockedID string) (bool, error)
+	// IsBlocking reports whether blockerID blocks blockedID (one direction only).
+	IsBlocking(ctx context.Context, blockerID, blockedID string) (bool, error)
+	// ListBlocked returns the accounts blockerID blocks, most recently blocked first, starting after the given
+	// cursor (nil for the first page).
+	ListBlocked(ctx context.Context, blockerID string, after *Cursor, limit int) ([]*model.AccountEdge, error)
+	// Mute reports whether a new mute was stored.
+	Mute(ctx context.Context, muterID, mutedID string) (bool, error)
+	// Unmute reports whether a mute was removed.
+	Unmute(ctx context.Context, muterID, mutedID string) (bool, error)
+	// ListMuted returns the accounts muterID mutes, ordered and paged like ListBlocked.
+	ListMuted(ctx context.Context, muterID string, after *Cursor, limit int) ([]*model.AccountEdge, error)
+}
+
+// NewConversation is the data needed to start a conversation.
+type NewConversation struct {
+	// MemberIDs lists every member, the creator included.
+	MemberIDs []string
+	Title     *string
+	// IsGroup is false for direct conversations, which have exactly two members and are unique per pair.
+	IsGroup bool
+}
+
+// ConversationMember is a member of a conversation and its read position.
+type ConversationMember struct {
+	AccountID string
+	// LastReadMessageID is the newest message the member has read, "" if none; LastReadAt is its creation time.
+	LastReadMessageID string
+	LastReadAt        time.Time
+}
+
+// MessageRepository stores conversations and their messages. A conversation only exists for its members:
+// methods taking a member or viewer ID return ErrNotFound for conversations that account is not in.
+// "Visible to" an account means sent by someone that account neither blocks nor is blocked by.
+type MessageRepository interface {
+	// CreateConversation stores a conversation with its members. For a direct conversation that already exists
+	// between the two members, it returns that one instead.
+	CreateConversation(ctx context.Context, c NewConversation) (*model.Conversation, error)
+	GetConversation(ctx context.Context, conversationID, memberID string) (*model.Conversation, error)
+	// ListConversations returns memberID's conversations, most recent message first, starting after the given
+	// cursor (nil for the first page).
+	ListConversations(ctx context.Context, memberID string, after *Cursor, limit int) ([]*model.ConversationEdge, error)
+	// Members returns the members of the given conversations, keyed by conversation ID, in joining order.
+	Members(ctx context.Context, conversationIDs []string) (map[string][]ConversationMember, error)
+	// LastMessages returns the newest message visible to viewerID of each of the given conversations, keyed by
+	// conversation ID. Conversations without one are absent.
+	LastMessages(ctx context.Context, viewerID string, conversationIDs []string) (map[string]*model.Message, error)
+	// UnreadCounts returns how many messages visible to memberID, sent by others, are newer than its read
+	// position in each of the given conversations, keyed by conversation ID.
+	UnreadCounts(ctx context.Context, memberID string, conversationIDs []string) (map[string]int, error)
+	// MarkRead moves memberID's read position forward to messageID, or to the newest message when messageID is
+	// "". It returns ErrNotFound if the message is not in the conversation.
+	MarkRead(ctx context.Context, conversationID, memberID, messageID string) error
+
+	// Send stores a message and moves the sender's read position to it.
+	Send(ctx context.Context, conversationID, senderID, body string) (*model.Message, error)
+	// GetMessage returns a message of one of memberID's conversations, deleted or not.
+	GetMessage(ctx context.Context, messageID, memberID string) (*model.Message, error)
+	// Edit replaces the body of senderID's message. It returns ErrNotFound if there is no such live message.
+	Edit(ctx context.Context, messageID, senderID, body string) (*model.Message, error)
+	// Delete clears the body of senderID's message and marks it deleted. It returns ErrNotFound if there is no
+	// such live message.
+	Delete(ctx context.Context, messageID, senderID string) (*model.Message, error)
+	// ListMessages returns the messages of a conversation visible to viewerID, newest first, starting after the
+	// given cursor (nil for the first page). Deleted messages are included without a body.
+	ListMessages(ctx context.Context, conversationID, viewerID string, after *Cursor, limit int) ([]*model.MessageEdge, error)
+	// Subscribe delivers the messages sent, edited or deleted in memberID's conversations that are visible to
+	// memberID, until ctx is done.
+	Subscribe(ctx context.Context, memberID string) (<-chan *model.Message, error)
+}
+
+// TimelineRepository maintains home timelines: for each account, the live posts of the accounts it follows.
+//
+// Timelines are filled on write for most authors. Authors with more than maxFollowers followers are skipped
+// on write and their posts are merged in by List instead, so one post never costs millions of rows.
+type TimelineRepository interface {
+	// FanOut copies a live post into its author's followers' timelines, unless the author has more than
+	// maxFollowers followers. It returns the number of timelines written.
+	FanOut(ctx context.Context, postID string, maxFollowers int) (int, error)
+	// Backfill copies up to limit of authorID's most recent posts into ownerID's timeline, unless the author
+	// has more than maxFollowers followers.
+	Backfill(ctx context.Context, ownerID, authorID string, maxFollowers, limit int) error
+	// Prune removes authorID's posts from ownerID's timeline.
+	Prune(ctx context.Context, ownerID, authorID string) error
+	// List returns ownerID's feed newest first, starting after the given cursor (nil for the first page):
+	// its timeline plus the posts of followed authors with more than maxFollowers followers. Posts ownerID may
+	// not see (as in PostRepository.GetVisible) or whose author ownerID muted are left out even if still on the
+	// timeline.
+	List(ctx context.Context, ownerID string, maxFollowers int, after *Cursor, limit int) ([]*model.PostEdge, error)
+}
+
+// NewNotification carries the columns written for a single notification row.
+type NewNotification struct {
+	RecipientID      string
+	TriggeringUserID string
+	Type             model.NotificationType
+	EntityID         string
+	CreatedAt        time.Time
+}
+
+// NotificationFilter narrows ListForRecipient and ListGroups. Zero values mean "no restriction".
+type NotificationFilter struct {
+	Types    []model.NotificationType
+	EntityID string
+	IsRead   *bool
+	// CreatedAfter is inclusive, CreatedBefore exclusive.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// NotificationGroupKey identifies the notifications of one type about one entity within one time window.
+// NEW_FOLLOWER and FOLLOW_REQUEST notifications name the follower as their entity, so they are grouped by
+// type alone and EntityID is empty.
+type NotificationGroupKey struct {
+	Type        model.NotificationType
+	EntityID    string
+	WindowStart time.Time
+}
+
+// String returns the key's stable text form, "type|entity|window start in Unix seconds".
+func (k NotificationGroupKey) String() string {
+	return fmt.Sprintf("%s|%s|%d", strings.ToLower(string(k.Type)), k.EntityID, k.WindowStart.Unix())
+}
+
+// ParseNotificationGroupKey parses the String form of a key.
+func ParseNotificationGroupKey(s string) (NotificationGroupKey, error) {
+	parts := strings.Split(s, "|")
+	if len(parts) != 3 {
+		return NotificationGroupKey{}, fmt.Errorf("store: invalid notification group key %q", s)
+	}
+	t := model.NotificationType(strings.ToUpper(parts[0]))
+	start, err := strconv.ParseInt(parts[2], 10, 64)
+	if !t.IsValid() || err != nil {
+		return NotificationGroupKey{}, fmt.Errorf("store: invalid notification group key %q", s)
+	}
+	return NotificationGroupKey{Type: t, EntityID: parts[1], WindowStart: time.Unix(start, 0).UTC()}, nil
+}
+
+// NotificationGroupActors is how many recent actors a NotificationGroup lists.
+const NotificationGroupActors = 3
+
+// NotificationGroup aggregates the notifications sharing a NotificationGroupKey.
+type NotificationGroup struct {
+	Key         NotificationGroupKey
+	LatestAt    time.Time
+	Count       int
+	UnreadCount int
+	ActorCount  int
+	// ActorIDs holds up to NotificationGroupActors distinct actors, most recent first.
+	ActorIDs []string
+}
+
+// Cursor returns the group's position in a ListGroups result.
+func (g *NotificationGroup) Cursor() Cursor {
+	return Cursor{CreatedAt: g.LatestAt, ID: g.Key.String()}
+}
+
+// DecodeNotificationGroupCursor parses a cursor produced by NotificationGroup.Cursor.
+func DecodeNotificationGroupCursor(s string) (Cursor, error) {
+	c, err := decodeCursor(s)
+	if err != nil {
+		return Cursor{}, err
+	}
+	if _, err := ParseNotificationGroupKey(c.ID); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, n NewNotification) error
+	// GetByID returns the notification with TriggeringUser populated, or ErrNotFound.
+	GetByID(ctx context.Context, notificationID string) (*model.Notification, error)
+	// ListForRecipient returns notifications newest first, with TriggeringUser populated, starting after the
+	// given cursor (nil for the first page). Like ListGroups and CountUnread, it leaves out notifications caused
+	// by accounts the recipient blocks, is blocked by or muted.
+	ListForRecipient(ctx context.Context, recipientID string, filter NotificationFilter, after *Cursor, limit int) ([]*model.NotificationEdge, error)
+	// ListGroups groups the notifications matching filter by NotificationGroupKey, where windows are
+	// consecutive spans of the given length starting at the Unix epoch. Groups are ordered by their newest
+	// notification, newest first, starting after the given cursor (nil for the first page).
+	ListGroups(ctx context.Context, recipientID string, filter NotificationFilter, window time.Duration, after *Cursor, limit int) ([]*NotificationGroup, error)
+	// CountUnread returns how many of recipientID's notifications are unread.
+	CountUnread(ctx context.Context, recipientID string) (int, error)
+	// MarkRead marks the listed notifications of recipientID as read. IDs of other recipients are ignored.
+	MarkRead(ctx context.Context, recipientID string, notificationIDs []string) error
+	// MarkAllRead marks every notification of recipientID created at or before before as read.
+	MarkAllRead(ctx context.Context, recipientID string, before time.Time) error
+	// Subscribe returns the notifications created for recipientID from now on, including those created by
+	// other server replicas. The channel is closed when ctx is done.
+	Subscribe(ctx context.Context, recipientID string) (<-chan *model.Notification, error)
+	// SubscribeUnreadCount returns recipientID's unread count each time a notification is created for them
+	// or marked read, on any server replica. The channel is closed when ctx is done.
+	SubscribeUnreadCount(ctx context.Context, recipientID string) (<-chan int, error)
+}
+
+// NotificationChannels says how an account receives one type of notification.
+type NotificationChannels struct {
+	InApp   bool
+	Email   bool
+	Webhook bool
+}
+
+// DefaultNotificationChannels apply to notification types an account has not configured.
+var DefaultNotificationChannels = NotificationChannels{InApp: true}
+
+// NotificationPreferences are an account's stored settings.
+type NotificationPreferences struct {
+	// Types holds the configured notification types; the others use DefaultNotificationChannels.
+	Types      map[model.NotificationType]NotificationChannels
+	WebhookURL string
+}
+
+// NotificationPreferencesUpdate lists the settings to change. Types replaces the channels of the listed types;
+// a nil WebhookURL is left untouched and an empty one removes it.
+type NotificationPreferencesUpdate struct {
+	Types      map[model.NotificationType]NotificationChannels
+	WebhookURL *string
+}
+
+// NotificationDelivery says how one recipient receives one notification, with the addresses for the
+// out-of-app channels.
+type NotificationDelivery struct {
+	NotificationChannels
+	Email      string
+	WebhookURL string // Webhook is only set when a URL is configured
+}
+
+type NotificationPreferenceRepository interface {
+	Get(ctx context.Context, accountID string) (*NotificationPreferences, error)
+	Update(ctx context.Context, accountID string, update NotificationPreferencesUpdate) error
+	// Deliveries returns how each recipient receives a notification of type t caused by actorID. Recipients
+	// that disabled every channel for t, muted actorID's new posts (for NEW_POST), block or are blocked by
+	// actorID, muted actorID, or do not exist are absent.
+	Deliveries(ctx context.Context, t model.NotificationType, actorID string, recipientIDs []string) (map[string]NotificationDelivery, error)
+	// MuteNewPosts stops NEW_POST notifications from authorID to accountID. It reports whether the mute is new.
+	MuteNewPosts(ctx context.Context, accountID, authorID string) (bool, error)
+	// UnmuteNewPosts reports whether a mute was removed.
+	UnmuteNewPosts(ctx context.Context, accountID, authorID string) (bool, error)
+	// MutedNewPostAuthors returns the authors accountID muted, most recently muted first.
+	MutedNewPostAuthors(ctx context.Context, accountID string) ([]string, error)
+}
+
+// NewProfile carries the columns written when creating a profile. ProfileID is the owning account's ID.
+type NewProfile struct {
+	ProfileID         string
+	Username          string
+	Email             string
+	FirstName         *string
+	MiddleName        *string
+	LastName          *string
+	Bio               *string
+	ProfilePictureURL *string
+	BannerPictureURL  *string
+	DateOfBirth       *string
+	Address           *string
+}
+
+// ProfileUpdate lists the columns to change. Nil fields are left untouched; an empty string clears the column
+// (Username cannot be cleared).
+type ProfileUpdate struct {
+	Username          *string
+	MiddleName        *string
+	Bio               *string
+	ProfilePictureURL *string
+	BannerPictureURL  *string
+	DateOfBirth       *string // YYYY-MM-DD
+	Address           *string
+}
+
+type ProfileRepository interface {
+	// Create returns ErrUsernameTaken for a duplicate username and ErrConflict for any other duplicate.
+	Create(ctx context.Context, input NewProfile) (*model.Profile, error)
+	// Update returns ErrNotFound when the profile does not exist and ErrUsernameTaken for a duplicate username.
+	Update(ctx context.Context, profileID string, update ProfileUpdate) (*model.Profile, error)
+	// GetByID returns ErrNotFound when no profile has the given ID.
+	GetByID(ctx context.Context, profileID string) (*model.Profile, error)
+	// List returns every profile except those of accounts blocking or blocked by viewerID ("" for anonymous
+	// viewers), ordered by username.
+	List(ctx context.Context, viewerID string) ([]*model.Profile, error)
+}
+
+// NewRefreshToken carries the columns written when issuing a refresh token. Only the token's digest is stored.
+type NewRefreshToken struct {
+	AccountID string
+	Digest    string
+	ExpiresAt time.Time
+}
+
+// RefreshTokenRepository keeps server-side state for refresh tokens so sessions can be rotated and revoked.
+// A token is live while it is neither revoked nor expired.
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t NewRefreshToken) error
+	// Rotate revokes the live token with oldDigest and stores newDigest for the same account in one step,
+	// returning that account's ID. It returns ErrNotFound when oldDigest does not name a live token.
+	Rotate(ctx context.Context, oldDigest, newDigest string, expiresAt time.Time) (accountID string, err error)
+	// Revoke revokes the live token with digest and returns its account's ID, or ErrNotFound.
+	Revoke(ctx context.Context, digest string) (accountID string, err error)
+	// RevokeAll revokes every live token of accountID and returns how many were revoked.
+	RevokeAll(ctx context.Context, accountID string) (int, error)
+}
+
+// RoleRepository holds roles granted to accounts in the database, in addition to any carried by token claims.
+// Role names are stored lower-case, e.g. "admin".
+type RoleRepository interface {
+	ListForAccount(ctx context.Context, accountID string) ([]string, error)
+	// Grant reports whether the role was newly granted (false if the account already had it).
+	Grant(ctx context.Context, accountID, role string) (bool, error)
+	// Revoke reports whether the account had the role.
+	Revoke(ctx context.Context, accountID, role string) (bool, error)
+}
+
+// NewComment carries the columns written when adding a comment. ParentID is empty for top-level comments.
+type NewComment struct {
+	PostID   string
+	ParentID string
+	AuthorID string
+	Content  string
+}
+
+// CommentRepository returns comments with Author populated (ID and name). Deleted comments are returned as
+// tombstones, with empty Content and DeletedAt set, so threads keep their shape.
+type CommentRepository interface {
+	// Create returns ErrNotFound when the post is missing or deleted, or the parent is not a live comment
+	// of the same post.
+	Create(ctx context.Context, input NewComment) (*model.Comment, error)
+	// GetByID returns ErrNotFound when no comment has the given ID.
+	GetByID(ctx context.Context, commentID string) (*model.Comment, error)
+	// Update changes the content of a live comment written by authorID, or returns ErrNotFound.
+	Update(ctx context.Context, commentID, authorID, content string) (*model.Comment, error)
+	// Delete soft-deletes a live comment, or returns ErrNotFound.
+	Delete(ctx context.Context, commentID string) (*model.Comment, error)
+	// List returns up to limit comments of postID whose parent is parentID ("" for top-level comments),
+	// oldest first, starting after the given cursor.
+	List(ctx context.Context, postID, parentID string, after *Cursor, limit int) ([]*model.CommentEdge, error)
+	// CountForPost counts the live comments of a post, replies included.
+	CountForPost(ctx context.Context, postID string) (int, error)
+}
+
+// Reaction target kinds.
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// ReactionTarget identifies the post or comment a reaction is attached to.
+type ReactionTarget struct {
+	Kind string // ReactionTargetPost or ReactionTargetComment
+	ID   string
+}
+
+// ReactionRepository stores one reaction per account and target. Reactions are lower-case names such as
+// "like". Per-reaction counts are maintained on write so reading them stays cheap.
+type ReactionRepository interface {
+	// Set records accountID's reaction to target, replacing any previous one. It reports whether the account
+	// had not reacted to target before.
+	Set(ctx context.Context, target ReactionTarget, accountID, reaction string) (created bool, err error)
+	// Remove reports whether accountID had a reaction to target that was removed.
+	Remove(ctx context.Context, target ReactionTarget, accountID string) (bool, error)
+	// Counts returns, for each of the given IDs of one kind, the number of accounts per reaction.
+	// IDs without reactions are absent from the result.
+	Counts(ctx context.Context, kind string, ids []string) (map[string]map[string]int, error)
+	// ForAccount returns accountID's reaction to each of the given IDs of one kind that it reacted to.
+	ForAccount(ctx context.Context, kind string, ids []string, accountID string) (map[string]string, error)
+}